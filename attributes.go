@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"net/http"
 	"reflect"
-	"runtime"
 	"slices"
 	"strings"
 
@@ -144,31 +143,16 @@ func AnyValueToString(v slog.Value) string {
 	return fmt.Sprintf("%+v", v.Any())
 }
 
+// AttrsToString renders values with ValueToString. See ValueFormat for other
+// time and duration formats.
 func AttrsToString(attrs ...slog.Attr) map[string]string {
-	output := make(map[string]string, len(attrs))
-
-	for i := range attrs {
-		attr := attrs[i]
-		k, v := attr.Key, attr.Value
-		output[k] = ValueToString(v)
-	}
-
-	return output
+	return ValueFormat{}.AttrsToString(attrs...)
 }
 
+// ValueToString renders times in UTC with time.Time.String and durations with
+// time.Duration.String. See ValueFormat for other formats.
 func ValueToString(v slog.Value) string {
-	v = v.Resolve()
-
-	switch v.Kind() {
-	case slog.KindAny, slog.KindLogValuer, slog.KindGroup:
-		return AnyValueToString(v)
-	case slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindString, slog.KindBool, slog.KindDuration:
-		return v.String()
-	case slog.KindTime:
-		return v.Time().UTC().String()
-	default:
-		return AnyValueToString(v)
-	}
+	return ValueFormat{}.ValueToString(v)
 }
 
 func ReplaceError(attrs []slog.Attr, errorKeys ...string) []slog.Attr {
@@ -260,26 +244,11 @@ func FormatRequest(req *http.Request, ignoreHeaders bool) map[string]any {
 }
 
 func Source(sourceKey string, r *slog.Record) slog.Attr {
-	fs := runtime.CallersFrames([]uintptr{r.PC})
-	f, _ := fs.Next()
-	var args []any
-	if f.Function != "" {
-		args = append(args, slog.String("function", f.Function))
-	}
-	if f.File != "" {
-		args = append(args, slog.String("file", f.File))
-	}
-	if f.Line != 0 {
-		args = append(args, slog.Int("line", f.Line))
-	}
-
-	return slog.Group(sourceKey, args...)
+	return SourceWithOptions(sourceKey, r, SourceOptions{})
 }
 
 func StringSource(sourceKey string, r *slog.Record) slog.Attr {
-	fs := runtime.CallersFrames([]uintptr{r.PC})
-	f, _ := fs.Next()
-	return slog.String(sourceKey, fmt.Sprintf("%s:%d (%s)", f.File, f.Line, f.Function))
+	return StringSourceWithOptions(sourceKey, r, SourceOptions{})
 }
 
 func FindAttribute(attrs []slog.Attr, groups []string, key string) (slog.Attr, bool) {
@@ -333,3 +302,31 @@ func RemoveEmptyAttrs(attrs []slog.Attr) []slog.Attr {
 		return RemoveEmptyAttrs(attr.Value.Group())
 	})
 }
+
+// mergeAttrsByKey applies the same merging rules as AttrsToMap (groups sharing
+// a key are merged, other duplicates are overwritten by the last value) while
+// keeping keys in order of first appearance.
+func mergeAttrsByKey(attrs []slog.Attr) []slog.Attr {
+	keys := make([]string, 0, len(attrs))
+	valuesByKey := map[string][]slog.Value{}
+
+	for _, attr := range attrs {
+		if _, ok := valuesByKey[attr.Key]; !ok {
+			keys = append(keys, attr.Key)
+		}
+		valuesByKey[attr.Key] = append(valuesByKey[attr.Key], attr.Value.Resolve())
+	}
+
+	output := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		output = append(output, slog.Attr{Key: key, Value: mergeAttrValues(valuesByKey[key]...)})
+	}
+
+	return output
+}
+
+func sortAttrsByKey(attrs []slog.Attr) {
+	slices.SortStableFunc(attrs, func(a, b slog.Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+}
@@ -0,0 +1,340 @@
+package slogcommon
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Binary wire format of a record (version 1). Integers are varints as defined by
+// encoding/binary, signed ones being zigzag encoded.
+//
+//	record   = magic:"SLG" version:byte time level:varint message:string attrs
+//	attrs    = count:uvarint attr*
+//	attr     = key:string value
+//	value    = kind:byte payload
+//	string   = length:uvarint bytes
+//	time     = seconds:varint nanoseconds:uvarint       (decoded as UTC)
+//
+//	kind  payload
+//	0     none                                          (nil KindAny)
+//	1     json:string                                   (KindAny, JSON encoded)
+//	2     varint                                        (KindInt64)
+//	3     uvarint                                       (KindUint64)
+//	4     8 bytes, little-endian IEEE 754               (KindFloat64)
+//	5     byte, 0 or 1                                  (KindBool)
+//	6     varint nanoseconds                            (KindDuration)
+//	7     time                                          (KindTime)
+//	8     string                                        (KindString)
+//	9     attrs                                         (KindGroup)
+const (
+	binaryMagic   = "SLG"
+	binaryVersion = 1
+	// binaryMaxDepth bounds group nesting, on both sides, protecting the decoder
+	// against deeply nested input and the encoder against recursive LogValuers.
+	binaryMaxDepth = 64
+)
+
+const (
+	binaryKindNil byte = iota
+	binaryKindJSON
+	binaryKindInt64
+	binaryKindUint64
+	binaryKindFloat64
+	binaryKindBool
+	binaryKindDuration
+	binaryKindTime
+	binaryKindString
+	binaryKindGroup
+)
+
+var (
+	ErrBinaryInvalidHeader = errors.New("slog binary: invalid header")
+	ErrBinaryTruncated     = errors.New("slog binary: truncated input")
+	ErrBinaryUnknownKind   = errors.New("slog binary: unknown value kind")
+	ErrBinaryTooDeep       = errors.New("slog binary: groups nested too deeply")
+)
+
+// AppendBinaryRecord encodes a record. attrs is the full attribute set, usually
+// built with AppendRecordAttrsToAttrs.
+func AppendBinaryRecord(dst []byte, record *slog.Record, attrs []slog.Attr) ([]byte, error) {
+	dst = append(dst, binaryMagic...)
+	dst = append(dst, binaryVersion)
+	dst = appendBinaryTime(dst, record.Time)
+	dst = binary.AppendVarint(dst, int64(record.Level))
+	dst = appendBinaryString(dst, record.Message)
+
+	return AppendBinaryAttrs(dst, attrs...)
+}
+
+// AppendBinaryAttrs encodes attributes. Groups nested deeper than the decoder
+// accepts are rejected with ErrBinaryTooDeep.
+func AppendBinaryAttrs(dst []byte, attrs ...slog.Attr) ([]byte, error) {
+	return appendBinaryAttrs(dst, attrs, 0)
+}
+
+func appendBinaryAttrs(dst []byte, attrs []slog.Attr, depth int) ([]byte, error) {
+	if depth > binaryMaxDepth {
+		return nil, ErrBinaryTooDeep
+	}
+
+	dst = binary.AppendUvarint(dst, uint64(len(attrs)))
+
+	var err error
+	for _, attr := range attrs {
+		dst = appendBinaryString(dst, attr.Key)
+		if dst, err = appendBinaryValue(dst, attr.Value, depth); err != nil {
+			return nil, err
+		}
+	}
+
+	return dst, nil
+}
+
+func appendBinaryValue(dst []byte, v slog.Value, depth int) ([]byte, error) {
+	// Resolve bounds LogValuer chains, recursive groups are bounded by depth
+	v = v.Resolve()
+
+	switch v.Kind() {
+	case slog.KindInt64:
+		dst = append(dst, binaryKindInt64)
+		return binary.AppendVarint(dst, v.Int64()), nil
+	case slog.KindUint64:
+		dst = append(dst, binaryKindUint64)
+		return binary.AppendUvarint(dst, v.Uint64()), nil
+	case slog.KindFloat64:
+		dst = append(dst, binaryKindFloat64)
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(v.Float64())), nil
+	case slog.KindBool:
+		dst = append(dst, binaryKindBool)
+		if v.Bool() {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case slog.KindDuration:
+		dst = append(dst, binaryKindDuration)
+		return binary.AppendVarint(dst, int64(v.Duration())), nil
+	case slog.KindTime:
+		dst = append(dst, binaryKindTime)
+		return appendBinaryTime(dst, v.Time()), nil
+	case slog.KindString:
+		dst = append(dst, binaryKindString)
+		return appendBinaryString(dst, v.String()), nil
+	case slog.KindGroup:
+		dst = append(dst, binaryKindGroup)
+		return appendBinaryAttrs(dst, v.Group(), depth+1)
+	default:
+		value := v.Any()
+		if value == nil {
+			return append(dst, binaryKindNil), nil
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("slog binary: %w", err)
+		}
+
+		dst = append(dst, binaryKindJSON)
+		return appendBinaryString(dst, string(data)), nil
+	}
+}
+
+func appendBinaryString(dst []byte, s string) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func appendBinaryTime(dst []byte, t time.Time) []byte {
+	dst = binary.AppendVarint(dst, t.Unix())
+	return binary.AppendUvarint(dst, uint64(t.Nanosecond()))
+}
+
+// DecodeBinaryRecord decodes a record encoded by AppendBinaryRecord. The returned
+// record holds no attribute: they are returned separately. KindAny values are
+// decoded from JSON into maps, slices and primitives.
+func DecodeBinaryRecord(data []byte) (slog.Record, []slog.Attr, error) {
+	d := binaryDecoder{data: data}
+
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic || data[len(binaryMagic)] != binaryVersion {
+		return slog.Record{}, nil, ErrBinaryInvalidHeader
+	}
+	d.data = d.data[len(binaryMagic)+1:]
+
+	t, err := d.time()
+	if err != nil {
+		return slog.Record{}, nil, err
+	}
+	level, err := d.varint()
+	if err != nil {
+		return slog.Record{}, nil, err
+	}
+	msg, err := d.string()
+	if err != nil {
+		return slog.Record{}, nil, err
+	}
+	attrs, err := d.attrs(0)
+	if err != nil {
+		return slog.Record{}, nil, err
+	}
+
+	return slog.NewRecord(t, slog.Level(level), msg, 0), attrs, nil
+}
+
+// DecodeBinaryAttrs decodes attributes encoded by AppendBinaryAttrs.
+func DecodeBinaryAttrs(data []byte) ([]slog.Attr, error) {
+	d := binaryDecoder{data: data}
+	return d.attrs(0)
+}
+
+type binaryDecoder struct {
+	data []byte
+}
+
+func (d *binaryDecoder) attrs(depth int) ([]slog.Attr, error) {
+	if depth > binaryMaxDepth {
+		return nil, ErrBinaryTooDeep
+	}
+
+	count, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	// each attribute takes at least 2 bytes: never trust the announced count
+	attrs := make([]slog.Attr, 0, min(count, uint64(len(d.data)/2)))
+	for i := uint64(0); i < count; i++ {
+		key, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := d.value(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, slog.Attr{Key: key, Value: value})
+	}
+
+	return attrs, nil
+}
+
+func (d *binaryDecoder) value(depth int) (slog.Value, error) {
+	if len(d.data) == 0 {
+		return slog.Value{}, ErrBinaryTruncated
+	}
+
+	kind := d.data[0]
+	d.data = d.data[1:]
+
+	switch kind {
+	case binaryKindNil:
+		return slog.AnyValue(nil), nil
+	case binaryKindJSON:
+		s, err := d.string()
+		if err != nil {
+			return slog.Value{}, err
+		}
+		var value any
+		if err := json.Unmarshal([]byte(s), &value); err != nil {
+			return slog.Value{}, fmt.Errorf("slog binary: %w", err)
+		}
+		return slog.AnyValue(value), nil
+	case binaryKindInt64:
+		i, err := d.varint()
+		return slog.Int64Value(i), err
+	case binaryKindUint64:
+		u, err := d.uvarint()
+		return slog.Uint64Value(u), err
+	case binaryKindFloat64:
+		if len(d.data) < 8 {
+			return slog.Value{}, ErrBinaryTruncated
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+		d.data = d.data[8:]
+		return slog.Float64Value(f), nil
+	case binaryKindBool:
+		if len(d.data) < 1 {
+			return slog.Value{}, ErrBinaryTruncated
+		}
+		b := d.data[0] != 0
+		d.data = d.data[1:]
+		return slog.BoolValue(b), nil
+	case binaryKindDuration:
+		i, err := d.varint()
+		return slog.DurationValue(time.Duration(i)), err
+	case binaryKindTime:
+		t, err := d.time()
+		return slog.TimeValue(t), err
+	case binaryKindString:
+		s, err := d.string()
+		return slog.StringValue(s), err
+	case binaryKindGroup:
+		attrs, err := d.attrs(depth + 1)
+		return slog.GroupValue(attrs...), err
+	default:
+		return slog.Value{}, ErrBinaryUnknownKind
+	}
+}
+
+func (d *binaryDecoder) varint() (int64, error) {
+	i, n := binary.Varint(d.data)
+	if n <= 0 {
+		return 0, ErrBinaryTruncated
+	}
+
+	d.data = d.data[n:]
+	return i, nil
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	u, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, ErrBinaryTruncated
+	}
+
+	d.data = d.data[n:]
+	return u, nil
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	length, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if length > uint64(len(d.data)) {
+		return "", ErrBinaryTruncated
+	}
+
+	s := string(d.data[:length])
+	d.data = d.data[length:]
+	return s, nil
+}
+
+func (d *binaryDecoder) time() (time.Time, error) {
+	sec, err := d.varint()
+	if err != nil {
+		return time.Time{}, err
+	}
+	nsec, err := d.uvarint()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if nsec >= uint64(time.Second) {
+		return time.Time{}, fmt.Errorf("slog binary: invalid nanoseconds %d", nsec)
+	}
+
+	t := time.Unix(sec, int64(nsec)).UTC()
+	if t.IsZero() {
+		return time.Time{}, nil
+	}
+
+	return t, nil
+}
@@ -0,0 +1,116 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryRecordRoundTrip(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	record := slog.NewRecord(now, slog.LevelWarn+1, "hello", 0)
+	attrs := []slog.Attr{
+		slog.String("str", "foo"),
+		slog.Int("int", -42),
+		slog.Uint64("uint", 42),
+		slog.Float64("float", 3.14),
+		slog.Bool("bool", true),
+		slog.Duration("dur", time.Minute),
+		slog.Time("time", now),
+		slog.Group("group", slog.String("a", "b"), slog.Group("nested", slog.Int("c", 1))),
+		slog.Any("nil", nil),
+		slog.Any("any", map[string]any{"x": []int{1, 2}}),
+		slog.Any("err", assert.AnError),
+		slog.Any("user", stubLogValuer),
+	}
+
+	data, err := AppendBinaryRecord(nil, &record, attrs)
+	is.NoError(err)
+
+	decoded, decodedAttrs, err := DecodeBinaryRecord(data)
+	is.NoError(err)
+	is.Equal(now, decoded.Time)
+	is.Equal(slog.LevelWarn+1, decoded.Level)
+	is.Equal("hello", decoded.Message)
+	is.Equal(0, decoded.NumAttrs())
+
+	is.Equal([]slog.Attr{
+		slog.String("str", "foo"),
+		slog.Int("int", -42),
+		slog.Uint64("uint", 42),
+		slog.Float64("float", 3.14),
+		slog.Bool("bool", true),
+		slog.Duration("dur", time.Minute),
+		slog.Time("time", now),
+		slog.Group("group", slog.String("a", "b"), slog.Group("nested", slog.Int("c", 1))),
+		slog.Any("nil", nil),
+		slog.Any("any", map[string]any{"x": []any{float64(1), float64(2)}}),
+		slog.Any("err", assert.AnError.Error()),
+		slog.Group("user", slog.String("name", "userName"), slog.String("password", "********")),
+	}, decodedAttrs)
+
+	// zero time
+	record = slog.NewRecord(time.Time{}, slog.LevelInfo, "", 0)
+	data, err = AppendBinaryRecord(nil, &record, nil)
+	is.NoError(err)
+	decoded, decodedAttrs, err = DecodeBinaryRecord(data)
+	is.NoError(err)
+	is.True(decoded.Time.IsZero())
+	is.Empty(decodedAttrs)
+}
+
+func TestBinaryAttrsRoundTrip(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	attrs := []slog.Attr{slog.String("a", "b"), slog.Group("g", slog.Bool("c", false))}
+	data, err := AppendBinaryAttrs(nil, attrs...)
+	is.NoError(err)
+
+	decoded, err := DecodeBinaryAttrs(data)
+	is.NoError(err)
+	is.Equal(attrs, decoded)
+
+	_, err = AppendBinaryAttrs(nil, slog.Any("chan", make(chan int)))
+	is.Error(err)
+
+	// the encoder enforces the decoder depth
+	_, err = AppendBinaryAttrs(nil, slog.Any("x", testRecursiveLogValuer{}))
+	is.ErrorIs(err, ErrBinaryTooDeep)
+}
+
+func TestDecodeBinaryErrors(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	_, _, err := DecodeBinaryRecord([]byte("JSON"))
+	is.ErrorIs(err, ErrBinaryInvalidHeader)
+
+	record := slog.NewRecord(time.Unix(1, 0), slog.LevelInfo, "msg", 0)
+	data, _ := AppendBinaryRecord(nil, &record, []slog.Attr{slog.String("key", "value")})
+	for i := 4; i < len(data); i++ {
+		_, _, err = DecodeBinaryRecord(data[:i])
+		is.ErrorIs(err, ErrBinaryTruncated, "length %d", i)
+	}
+
+	_, err = DecodeBinaryAttrs([]byte{1, 1, 'k', 42})
+	is.ErrorIs(err, ErrBinaryUnknownKind)
+
+	// huge announced count
+	_, err = DecodeBinaryAttrs([]byte{0xff, 0xff, 0xff, 0xff, 0x0f})
+	is.ErrorIs(err, ErrBinaryTruncated)
+
+	// nesting
+	deep := []byte{}
+	for i := 0; i < 100; i++ {
+		deep = append(deep, 1, 1, 'g', binaryKindGroup)
+	}
+	deep = append(deep, 0)
+	_, err = DecodeBinaryAttrs(deep)
+	is.ErrorIs(err, ErrBinaryTooDeep)
+}
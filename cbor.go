@@ -0,0 +1,102 @@
+package slogcommon
+
+import (
+	"encoding/binary"
+	"log/slog"
+	"math"
+	"time"
+)
+
+const (
+	cborMajorUint   byte = 0 << 5
+	cborMajorNegInt byte = 1 << 5
+	cborMajorBytes  byte = 2 << 5
+	cborMajorText   byte = 3 << 5
+	cborMajorArray  byte = 4 << 5
+	cborMajorMap    byte = 5 << 5
+	cborMajorTag    byte = 6 << 5
+
+	cborFalse   byte = 0xf4
+	cborTrue    byte = 0xf5
+	cborNull    byte = 0xf6
+	cborFloat64 byte = 0xfb
+
+	cborTagEpochTime = 1
+)
+
+// AppendCBOR encodes attributes as a CBOR map (RFC 8949). Groups become nested
+// maps, times use tag 1 (epoch seconds) and durations are int64 nanoseconds.
+func AppendCBOR(dst []byte, attrs ...slog.Attr) []byte {
+	return appendEncodedAttrs(cborEncoder{}, dst, attrs, 0)
+}
+
+type cborEncoder struct{}
+
+func (cborEncoder) appendHeader(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), n)
+	}
+}
+
+func (cborEncoder) appendNil(dst []byte) []byte {
+	return append(dst, cborNull)
+}
+
+func (cborEncoder) appendBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, cborTrue)
+	}
+	return append(dst, cborFalse)
+}
+
+func (e cborEncoder) appendInt(dst []byte, i int64) []byte {
+	if i >= 0 {
+		return e.appendHeader(dst, cborMajorUint, uint64(i))
+	}
+	return e.appendHeader(dst, cborMajorNegInt, uint64(-(i + 1)))
+}
+
+func (e cborEncoder) appendUint(dst []byte, u uint64) []byte {
+	return e.appendHeader(dst, cborMajorUint, u)
+}
+
+func (cborEncoder) appendFloat(dst []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(dst, cborFloat64), math.Float64bits(f))
+}
+
+func (e cborEncoder) appendString(dst []byte, s string) []byte {
+	dst = e.appendHeader(dst, cborMajorText, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func (e cborEncoder) appendBytes(dst []byte, b []byte) []byte {
+	dst = e.appendHeader(dst, cborMajorBytes, uint64(len(b)))
+	return append(dst, b...)
+}
+
+// appendTime uses tag 1: an integer when the time has no fractional part, a
+// float64 otherwise.
+func (e cborEncoder) appendTime(dst []byte, t time.Time) []byte {
+	dst = e.appendHeader(dst, cborMajorTag, cborTagEpochTime)
+	if t.Nanosecond() == 0 {
+		return e.appendInt(dst, t.Unix())
+	}
+	return e.appendFloat(dst, float64(t.Unix())+float64(t.Nanosecond())/float64(time.Second))
+}
+
+func (e cborEncoder) appendArrayHeader(dst []byte, n int) []byte {
+	return e.appendHeader(dst, cborMajorArray, uint64(n))
+}
+
+func (e cborEncoder) appendMapHeader(dst []byte, n int) []byte {
+	return e.appendHeader(dst, cborMajorMap, uint64(n))
+}
@@ -0,0 +1,70 @@
+package slogcommon
+
+import (
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Expected values come from the examples of RFC 8949, appendix A.
+func TestCBOREncoder(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	enc := cborEncoder{}
+	h := func(b []byte) string { return hex.EncodeToString(b) }
+
+	is.Equal("00", h(enc.appendInt(nil, 0)))
+	is.Equal("17", h(enc.appendInt(nil, 23)))
+	is.Equal("1818", h(enc.appendInt(nil, 24)))
+	is.Equal("1903e8", h(enc.appendInt(nil, 1000)))
+	is.Equal("1a000f4240", h(enc.appendInt(nil, 1000000)))
+	is.Equal("1b000000e8d4a51000", h(enc.appendInt(nil, 1000000000000)))
+	is.Equal("1bffffffffffffffff", h(enc.appendUint(nil, math.MaxUint64)))
+	is.Equal("20", h(enc.appendInt(nil, -1)))
+	is.Equal("3863", h(enc.appendInt(nil, -100)))
+	is.Equal("3903e7", h(enc.appendInt(nil, -1000)))
+	is.Equal("3b7fffffffffffffff", h(enc.appendInt(nil, math.MinInt64)))
+	is.Equal("fb3ff199999999999a", h(enc.appendFloat(nil, 1.1)))
+	is.Equal("f4", h(enc.appendBool(nil, false)))
+	is.Equal("f5", h(enc.appendBool(nil, true)))
+	is.Equal("f6", h(enc.appendNil(nil)))
+	is.Equal("6161", h(enc.appendString(nil, "a")))
+	is.Equal("62c3bc", h(enc.appendString(nil, "ü")))
+	is.Equal("4401020304", h(enc.appendBytes(nil, []byte{1, 2, 3, 4})))
+	is.Equal("83", h(enc.appendArrayHeader(nil, 3)))
+	is.Equal("a2", h(enc.appendMapHeader(nil, 2)))
+	is.Equal("c11a514b67b0", h(enc.appendTime(nil, time.Unix(1363896240, 0))))
+	is.Equal("c1fb41d452d9ec200000", h(enc.appendTime(nil, time.Unix(1363896240, 500000000))))
+}
+
+func TestAppendCBOR(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	output := AppendCBOR(nil,
+		slog.Int("a", 1),
+		slog.Group("b", slog.Duration("c", time.Second)),
+		slog.Any("d", []string{"e"}),
+		slog.Any("f", []byte{1}),
+		slog.Any("g", &struct{ H int }{1}),
+	)
+
+	is.Equal(
+		"a5"+
+			"6161"+"01"+
+			"6162"+"a1"+"6163"+"1a3b9aca00"+
+			"6164"+"81"+"6165"+
+			"6166"+"4101"+
+			"6167"+"66"+hex.EncodeToString([]byte("&{H:1}")),
+		hex.EncodeToString(output),
+	)
+
+	// recursive LogValuers are cut off
+	output = AppendCBOR(nil, slog.Any("x", testRecursiveLogValuer{}))
+	is.Contains(string(output), NormalizerMaxDepthMarker)
+}
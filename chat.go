@@ -0,0 +1,338 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	slackMaxSectionText      = 3000
+	slackMaxFieldText        = 2000
+	slackMaxFieldsPerSection = 10
+	teamsMaxFieldText        = 2000
+	mattermostMaxFieldText   = 2000
+	telegramMaxMessageLength = 4096
+	chatIndent               = "    "
+)
+
+// Limits apply to escaped values, which are never cut in the middle of an escape
+// sequence.
+type ChatRenderOptions struct {
+	// MaxFieldLength overrides the platform limit for a single escaped value (in
+	// runes).
+	MaxFieldLength int
+	// TruncateSuffix defaults to "…".
+	TruncateSuffix string
+	// Color overrides the default level colors (hex notation, e.g. "#FF0000").
+	Color func(level slog.Level) string
+}
+
+type chatField struct {
+	key   string
+	value string
+	depth int
+	group bool
+}
+
+// ChatLevelColor returns the default color of a level, in hex notation.
+func ChatLevelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "#FF0000"
+	case level >= slog.LevelWarn:
+		return "#FFA500"
+	case level >= slog.LevelInfo:
+		return "#63C5DA"
+	default:
+		return "#808080"
+	}
+}
+
+func (o ChatRenderOptions) color(level slog.Level) string {
+	if o.Color != nil {
+		return o.Color(level)
+	}
+
+	return ChatLevelColor(level)
+}
+
+func (o ChatRenderOptions) truncate(s string, limit int) string {
+	if o.MaxFieldLength > 0 {
+		limit = o.MaxFieldLength
+	}
+
+	suffix := o.TruncateSuffix
+	if suffix == "" {
+		suffix = "…"
+	}
+
+	return truncateWithSuffix(s, limit, suffix)
+}
+
+// truncateEscaped escapes s and truncates the output to limit runes, rune by rune
+// of the input, so that escape sequences are kept whole.
+func (o ChatRenderOptions) truncateEscaped(s string, limit int, escape func(string) string) string {
+	if o.MaxFieldLength > 0 {
+		limit = o.MaxFieldLength
+	}
+
+	escaped := escape(s)
+	if limit <= 0 || utf8.RuneCountInString(escaped) <= limit {
+		return escaped
+	}
+
+	suffix := o.TruncateSuffix
+	if suffix == "" {
+		suffix = "…"
+	}
+	suffix = escape(suffix)
+	budget := limit - utf8.RuneCountInString(suffix)
+
+	var sb strings.Builder
+	n := 0
+	for _, r := range s {
+		e := escape(string(r))
+		if n += utf8.RuneCountInString(e); n > budget {
+			break
+		}
+		sb.WriteString(e)
+	}
+
+	return sb.String() + suffix
+}
+
+// RecordToSlackBlocks renders a Slack message made of Block Kit sections, wrapped
+// in a colored attachment.
+func RecordToSlackBlocks(record *slog.Record, attrs []slog.Attr, opts ChatRenderOptions) map[string]any {
+	blocks := []any{
+		slackSection("*" + opts.truncateEscaped(record.Message, slackMaxSectionText-2, slackEscape) + "*"),
+	}
+
+	var fields []any
+	for _, field := range flattenChatFields(attrs, 0) {
+		text := strings.Repeat(chatIndent, field.depth) + "*" + slackEscape(field.key) + "*"
+		if !field.group {
+			text += "\n" + opts.truncateEscaped(field.value, max(0, slackMaxFieldText-utf8.RuneCountInString(text)-1), slackEscape)
+		}
+
+		fields = append(fields, map[string]any{"type": "mrkdwn", "text": text})
+		if len(fields) == slackMaxFieldsPerSection {
+			blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+			fields = nil
+		}
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+	}
+
+	return map[string]any{
+		"text": opts.truncate(record.Message, slackMaxSectionText),
+		"attachments": []any{
+			map[string]any{
+				"color":  opts.color(record.Level),
+				"blocks": blocks,
+			},
+		},
+	}
+}
+
+// RecordToAdaptiveCard renders a Microsoft Teams message holding an Adaptive Card.
+func RecordToAdaptiveCard(record *slog.Record, attrs []slog.Attr, opts ChatRenderOptions) map[string]any {
+	facts := []any{}
+	for _, field := range flattenChatFields(attrs, 0) {
+		facts = append(facts, map[string]any{
+			"title": strings.Repeat(chatIndent, field.depth) + markdownEscape(field.key),
+			"value": opts.truncateEscaped(field.value, teamsMaxFieldText, markdownEscape),
+		})
+	}
+
+	body := []any{
+		map[string]any{
+			"type":   "TextBlock",
+			"text":   opts.truncateEscaped(record.Message, teamsMaxFieldText, markdownEscape),
+			"weight": "Bolder",
+			"size":   "Medium",
+			"color":  adaptiveCardColor(record.Level),
+			"wrap":   true,
+		},
+	}
+	if len(facts) > 0 {
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{
+			map[string]any{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
+
+// RecordToMattermostAttachment renders a Mattermost message attachment.
+func RecordToMattermostAttachment(record *slog.Record, attrs []slog.Attr, opts ChatRenderOptions) map[string]any {
+	fields := []any{}
+	for _, field := range flattenChatFields(attrs, 0) {
+		fields = append(fields, map[string]any{
+			"short": field.depth == 0 && !field.group,
+			"title": strings.Repeat(chatIndent, field.depth) + field.key,
+			"value": opts.truncateEscaped(field.value, mattermostMaxFieldText, markdownEscape),
+		})
+	}
+
+	return map[string]any{
+		"attachments": []any{
+			map[string]any{
+				"fallback": record.Message,
+				"color":    opts.color(record.Level),
+				"text":     opts.truncateEscaped(record.Message, mattermostMaxFieldText, markdownEscape),
+				"fields":   fields,
+			},
+		},
+	}
+}
+
+// RecordToTelegramMarkdownV2 renders a message for the MarkdownV2 parse mode.
+func RecordToTelegramMarkdownV2(record *slog.Record, attrs []slog.Attr, opts ChatRenderOptions) string {
+	return renderTelegram(record, attrs, opts, telegramMarkdownV2Escape, "*", "*")
+}
+
+// RecordToTelegramHTML renders a message for the HTML parse mode.
+func RecordToTelegramHTML(record *slog.Record, attrs []slog.Attr, opts ChatRenderOptions) string {
+	return renderTelegram(record, attrs, opts, htmlEscape, "<b>", "</b>")
+}
+
+// renderTelegram writes one field per line. Lines that would exceed the message
+// limit are dropped as a whole, so that the markup is never cut in the middle.
+func renderTelegram(record *slog.Record, attrs []slog.Attr, opts ChatRenderOptions, escape func(string) string, boldOpen string, boldClose string) string {
+	suffix := opts.TruncateSuffix
+	if suffix == "" {
+		suffix = "…"
+	}
+	budget := telegramMaxMessageLength - utf8.RuneCountInString(suffix) - 1
+
+	var sb strings.Builder
+	sb.WriteString(telegramLevelEmoji(record.Level))
+	sb.WriteString(" ")
+	sb.WriteString(boldOpen)
+	sb.WriteString(opts.truncateEscaped(record.Message, telegramMaxMessageLength/2, escape))
+	sb.WriteString(boldClose)
+	sb.WriteString("\n")
+	length := utf8.RuneCountInString(sb.String())
+
+	for _, field := range flattenChatFields(attrs, 0) {
+		line := "\n" + strings.Repeat(chatIndent, field.depth) + boldOpen + escape(field.key) + boldClose
+		if !field.group {
+			line += ": " + opts.truncateEscaped(field.value, telegramMaxMessageLength, escape)
+		}
+
+		n := utf8.RuneCountInString(line)
+		if length+n > budget {
+			sb.WriteString("\n")
+			sb.WriteString(escape(suffix))
+			break
+		}
+
+		length += n
+		sb.WriteString(line)
+	}
+
+	return sb.String()
+}
+
+func flattenChatFields(attrs []slog.Attr, depth int) []chatField {
+	var fields []chatField
+
+	for _, attr := range mergeAttrsByKey(attrs) {
+		if attr.Value.Kind() == slog.KindGroup {
+			fields = append(fields, chatField{key: attr.Key, depth: depth, group: true})
+			fields = append(fields, flattenChatFields(attr.Value.Group(), depth+1)...)
+			continue
+		}
+
+		fields = append(fields, chatField{key: attr.Key, value: ValueToString(attr.Value), depth: depth})
+	}
+
+	return fields
+}
+
+func slackSection(text string) map[string]any {
+	return map[string]any{
+		"type": "section",
+		"text": map[string]any{"type": "mrkdwn", "text": text},
+	}
+}
+
+func adaptiveCardColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "Attention"
+	case level >= slog.LevelWarn:
+		return "Warning"
+	case level >= slog.LevelInfo:
+		return "Accent"
+	default:
+		return "Default"
+	}
+}
+
+func telegramLevelEmoji(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "🔴"
+	case level >= slog.LevelWarn:
+		return "🟠"
+	case level >= slog.LevelInfo:
+		return "🔵"
+	default:
+		return "⚪"
+	}
+}
+
+func truncateWithSuffix(s string, limit int, suffix string) string {
+	if limit <= 0 || utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	suffixLength := utf8.RuneCountInString(suffix)
+	if suffixLength > limit {
+		// the suffix does not fit: plain cut
+		return truncateRunes(s, limit)
+	}
+
+	return truncateRunes(s, limit-suffixLength) + suffix
+}
+
+var (
+	slackEscaper            = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	htmlEscaper             = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+	markdownEscaper         = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "~", `\~`)
+	telegramMarkdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+		">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+)
+
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+func htmlEscape(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func telegramMarkdownV2Escape(s string) string {
+	return telegramMarkdownEscaper.Replace(s)
+}
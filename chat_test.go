@@ -0,0 +1,145 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatLevelColor(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("#808080", ChatLevelColor(slog.LevelDebug))
+	is.Equal("#63C5DA", ChatLevelColor(slog.LevelInfo))
+	is.Equal("#FFA500", ChatLevelColor(slog.LevelWarn+1))
+	is.Equal("#FF0000", ChatLevelColor(slog.LevelError+4))
+}
+
+func TestRecordToSlackBlocks(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	record := slog.NewRecord(time.Now(), slog.LevelError, "a <b> & c", 0)
+	attrs := []slog.Attr{
+		slog.String("user", "<john>"),
+		slog.Group("http", slog.Int("status", 500)),
+		slog.String("long", strings.Repeat("x", 3000)),
+	}
+
+	output := RecordToSlackBlocks(&record, attrs, ChatRenderOptions{})
+	is.Equal("a <b> & c", output["text"])
+
+	attachment := output["attachments"].([]any)[0].(map[string]any)
+	is.Equal("#FF0000", attachment["color"])
+
+	blocks := attachment["blocks"].([]any)
+	is.Len(blocks, 2)
+	is.Equal(slackSection("*a &lt;b&gt; &amp; c*"), blocks[0])
+
+	fields := blocks[1].(map[string]any)["fields"].([]any)
+	is.Len(fields, 4)
+	is.Equal(map[string]any{"type": "mrkdwn", "text": "*user*\n&lt;john&gt;"}, fields[0])
+	is.Equal(map[string]any{"type": "mrkdwn", "text": "*http*"}, fields[1])
+	is.Equal(map[string]any{"type": "mrkdwn", "text": "    *status*\n500"}, fields[2])
+
+	long := fields[3].(map[string]any)["text"].(string)
+	is.Equal(2000, utf8.RuneCountInString(long))
+	is.True(strings.HasSuffix(long, "x…"))
+
+	// limits apply to escaped text, without cutting entities
+	amps := strings.Repeat("&", 3000)
+	record = slog.NewRecord(time.Now(), slog.LevelError, amps, 0)
+	blocks = RecordToSlackBlocks(&record, []slog.Attr{slog.String("amps", amps)}, ChatRenderOptions{})["attachments"].([]any)[0].(map[string]any)["blocks"].([]any)
+	message := blocks[0].(map[string]any)["text"].(map[string]any)["text"].(string)
+	is.LessOrEqual(utf8.RuneCountInString(message), 3000)
+	is.True(strings.HasSuffix(message, "&amp;…*"))
+	field := blocks[1].(map[string]any)["fields"].([]any)[0].(map[string]any)["text"].(string)
+	is.LessOrEqual(utf8.RuneCountInString(field), 2000)
+	is.True(strings.HasSuffix(field, "&amp;…"))
+	record = slog.NewRecord(time.Now(), slog.LevelError, "a <b> & c", 0)
+
+	// 10 fields max per section
+	attrs = nil
+	for i := 0; i < 12; i++ {
+		attrs = append(attrs, slog.Int(strings.Repeat("k", i+1), i))
+	}
+	blocks = RecordToSlackBlocks(&record, attrs, ChatRenderOptions{})["attachments"].([]any)[0].(map[string]any)["blocks"].([]any)
+	is.Len(blocks, 3)
+	is.Len(blocks[1].(map[string]any)["fields"], 10)
+	is.Len(blocks[2].(map[string]any)["fields"], 2)
+}
+
+func TestRecordToAdaptiveCard(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	record := slog.NewRecord(time.Now(), slog.LevelWarn, "disk *almost* full", 0)
+	attrs := []slog.Attr{slog.Group("disk", slog.String("path", "/var/log_1"))}
+
+	output := RecordToAdaptiveCard(&record, attrs, ChatRenderOptions{MaxFieldLength: 5})
+	content := output["attachments"].([]any)[0].(map[string]any)["content"].(map[string]any)
+	is.Equal("AdaptiveCard", content["type"])
+
+	body := content["body"].([]any)
+	is.Equal("disk…", body[0].(map[string]any)["text"])
+	is.Equal("Warning", body[0].(map[string]any)["color"])
+	is.Equal([]any{
+		map[string]any{"title": "disk", "value": ""},
+		map[string]any{"title": "    path", "value": "/var…"},
+	}, body[1].(map[string]any)["facts"])
+}
+
+func TestRecordToMattermostAttachment(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "user_created", 0)
+	attrs := []slog.Attr{slog.String("name", "john_doe"), slog.Group("meta", slog.Bool("admin", true))}
+
+	output := RecordToMattermostAttachment(&record, attrs, ChatRenderOptions{Color: func(slog.Level) string { return "#000000" }})
+	is.Equal(map[string]any{
+		"attachments": []any{
+			map[string]any{
+				"fallback": "user_created",
+				"color":    "#000000",
+				"text":     `user\_created`,
+				"fields": []any{
+					map[string]any{"short": true, "title": "name", "value": `john\_doe`},
+					map[string]any{"short": false, "title": "meta", "value": ""},
+					map[string]any{"short": false, "title": "    admin", "value": "true"},
+				},
+			},
+		},
+	}, output)
+}
+
+func TestRecordToTelegram(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	record := slog.NewRecord(time.Now(), slog.LevelError, "failed (code 1.2)!", 0)
+	attrs := []slog.Attr{slog.String("path", "/a-b"), slog.Group("req", slog.String("ua", "<curl>"))}
+
+	is.Equal(
+		"🔴 *failed \\(code 1\\.2\\)\\!*\n\n*path*: /a\\-b\n*req*\n    *ua*: <curl\\>",
+		RecordToTelegramMarkdownV2(&record, attrs, ChatRenderOptions{}),
+	)
+	is.Equal(
+		"🔴 <b>failed (code 1.2)!</b>\n\n<b>path</b>: /a-b\n<b>req</b>\n    <b>ua</b>: &lt;curl&gt;",
+		RecordToTelegramHTML(&record, attrs, ChatRenderOptions{}),
+	)
+
+	// message limit
+	attrs = nil
+	for i := 0; i < 100; i++ {
+		attrs = append(attrs, slog.String(strings.Repeat("k", i+1), strings.Repeat("<", 100)))
+	}
+	output := RecordToTelegramHTML(&record, attrs, ChatRenderOptions{})
+	is.LessOrEqual(utf8.RuneCountInString(output), 4096)
+	is.True(strings.HasSuffix(output, "</b>: "+strings.Repeat("&lt;", 100)+"\n…"))
+}
@@ -0,0 +1,208 @@
+package slogcommon
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	CloudEventsSpecVersion     = "1.0"
+	CloudEventsContentType     = "application/cloudevents+json"
+	CloudEventsHTTPPrefix      = "ce-"
+	CloudEventsKafkaPrefix     = "ce_"
+	cloudEventsDefaultType     = "slog.record"
+	cloudEventsDataContentType = "application/json"
+)
+
+type CloudEventMode int
+
+const (
+	CloudEventStructured CloudEventMode = iota
+	CloudEventBinary
+)
+
+type CloudEventOptions struct {
+	// Source is the URI-reference identifying the producer (required by the spec).
+	Source  string
+	Subject string
+
+	// TypePrefix defaults to "slog.record". The level is appended: "slog.record.error".
+	TypePrefix string
+	// TypeAttrKey, when set, reads the event type from this top-level attribute
+	// instead of deriving it from the level. The attribute is removed from data.
+	TypeAttrKey string
+
+	// ID defaults to a random 128-bit hex string.
+	ID func() string
+
+	// MessageKey and LevelKey default to slog.MessageKey and slog.LevelKey.
+	MessageKey string
+	LevelKey   string
+}
+
+type CloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id"`
+	Source          string         `json:"source"`
+	Type            string         `json:"type"`
+	Subject         string         `json:"subject,omitempty"`
+	Time            string         `json:"time,omitempty"`
+	DataContentType string         `json:"datacontenttype,omitempty"`
+	Data            map[string]any `json:"data,omitempty"`
+}
+
+// RecordToCloudEvent wraps the attributes map of a record into a CloudEvents 1.0
+// envelope. attrs is the full attribute set, usually built with AppendRecordAttrsToAttrs.
+func RecordToCloudEvent(record *slog.Record, attrs []slog.Attr, opts CloudEventOptions) CloudEvent {
+	eventType := ""
+	if opts.TypeAttrKey != "" {
+		if attr, ok := FindAttrByKey(attrs, opts.TypeAttrKey); ok {
+			eventType = ValueToString(attr.Value)
+			attrs = removeAttrsByKey(attrs, opts.TypeAttrKey)
+		}
+	}
+	if eventType == "" {
+		prefix := opts.TypePrefix
+		if prefix == "" {
+			prefix = cloudEventsDefaultType
+		}
+		eventType = prefix + "." + strings.ToLower(DefaultLevelRegistry.Name(record.Level))
+	}
+
+	id := ""
+	if opts.ID != nil {
+		id = opts.ID()
+	} else {
+		id = randomID()
+	}
+
+	messageKey := opts.MessageKey
+	if messageKey == "" {
+		messageKey = slog.MessageKey
+	}
+	levelKey := opts.LevelKey
+	if levelKey == "" {
+		levelKey = slog.LevelKey
+	}
+
+	data := AttrsToMap(attrs...)
+	data[messageKey] = record.Message
+	data[levelKey] = DefaultLevelRegistry.Name(record.Level)
+
+	event := CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              id,
+		Source:          opts.Source,
+		Type:            eventType,
+		Subject:         opts.Subject,
+		DataContentType: cloudEventsDataContentType,
+		Data:            data,
+	}
+
+	if !record.Time.IsZero() {
+		event.Time = record.Time.UTC().Format(time.RFC3339Nano)
+	}
+
+	return event
+}
+
+// CloudEventStructuredBody returns the event in structured content mode.
+func CloudEventStructuredBody(event CloudEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// CloudEventBinaryHeaders returns the context attributes as transport headers, using
+// CloudEventsHTTPPrefix ("ce-") for HTTP or CloudEventsKafkaPrefix ("ce_") for Kafka.
+// The data content type must be sent as the regular Content-Type header.
+func CloudEventBinaryHeaders(event CloudEvent, prefix string) map[string]string {
+	headers := map[string]string{
+		prefix + "specversion": event.SpecVersion,
+		prefix + "id":          cloudEventHeaderEscape(event.ID),
+		prefix + "source":      cloudEventHeaderEscape(event.Source),
+		prefix + "type":        cloudEventHeaderEscape(event.Type),
+	}
+
+	if event.Subject != "" {
+		headers[prefix+"subject"] = cloudEventHeaderEscape(event.Subject)
+	}
+	if event.Time != "" {
+		headers[prefix+"time"] = event.Time
+	}
+
+	return headers
+}
+
+// CloudEventBinaryBody returns the event data in binary content mode.
+func CloudEventBinaryBody(event CloudEvent) ([]byte, error) {
+	return json.Marshal(event.Data)
+}
+
+func NewCloudEventHTTPRequest(ctx context.Context, endpoint string, event CloudEvent, mode CloudEventMode) (*http.Request, error) {
+	var body []byte
+	var err error
+
+	if mode == CloudEventBinary {
+		body, err = CloudEventBinaryBody(event)
+	} else {
+		body, err = CloudEventStructuredBody(event)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == CloudEventBinary {
+		req.Header.Set("Content-Type", event.DataContentType)
+		for k, v := range CloudEventBinaryHeaders(event, CloudEventsHTTPPrefix) {
+			req.Header.Set(k, v)
+		}
+	} else {
+		req.Header.Set("Content-Type", CloudEventsContentType)
+	}
+
+	return req, nil
+}
+
+func cloudEventHeaderEscape(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '%' {
+			sb.WriteByte('%')
+			sb.WriteString(strings.ToUpper(hex.EncodeToString([]byte{c})))
+			continue
+		}
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+// randomID returns 32 random hex chars, as used by CloudEvents and Sentry event ids.
+func randomID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func removeAttrsByKey(attrs []slog.Attr, key string) []slog.Attr {
+	output := make([]slog.Attr, 0, len(attrs))
+	for i := range attrs {
+		if attrs[i].Key != key {
+			output = append(output, attrs[i])
+		}
+	}
+
+	return output
+}
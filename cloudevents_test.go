@@ -0,0 +1,101 @@
+package slogcommon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordToCloudEvent(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := slog.NewRecord(now, slog.LevelError, "boom", 0)
+	attrs := []slog.Attr{slog.String("user", "john"), slog.Group("http", slog.Int("status", 500))}
+
+	event := RecordToCloudEvent(&record, attrs, CloudEventOptions{
+		Source: "/api",
+		ID:     func() string { return "42" },
+	})
+	is.Equal(CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              "42",
+		Source:          "/api",
+		Type:            "slog.record.error",
+		Time:            "2024-01-02T03:04:05Z",
+		DataContentType: "application/json",
+		Data: map[string]any{
+			"user":  "john",
+			"http":  map[string]any{"status": int64(500)},
+			"msg":   "boom",
+			"level": "ERROR",
+		},
+	}, event)
+
+	// type from attribute
+	attrs = append(attrs, slog.String("event_type", "com.example.order.failed"))
+	event = RecordToCloudEvent(&record, attrs, CloudEventOptions{Source: "/api", TypeAttrKey: "event_type", MessageKey: "message"})
+	is.Equal("com.example.order.failed", event.Type)
+	is.NotContains(event.Data, "event_type")
+	is.Equal("boom", event.Data["message"])
+	is.Len(event.ID, 32)
+
+	event = RecordToCloudEvent(&record, nil, CloudEventOptions{Source: "/api", TypePrefix: "com.example.log", TypeAttrKey: "missing"})
+	is.Equal("com.example.log.error", event.Type)
+
+	// levels are named by DefaultLevelRegistry
+	record = slog.NewRecord(time.Time{}, LevelTrace, "m", 0)
+	event = RecordToCloudEvent(&record, nil, CloudEventOptions{Source: "/api"})
+	is.Equal("slog.record.trace", event.Type)
+	is.Equal("TRACE", event.Data["level"])
+}
+
+func TestCloudEventModes(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	event := CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              "42",
+		Source:          "/my source",
+		Type:            "slog.record.info",
+		Time:            "2024-01-02T03:04:05Z",
+		DataContentType: "application/json",
+		Data:            map[string]any{"msg": "hello"},
+	}
+
+	body, err := CloudEventStructuredBody(event)
+	is.NoError(err)
+	is.JSONEq(`{"specversion":"1.0","id":"42","source":"/my source","type":"slog.record.info","time":"2024-01-02T03:04:05Z","datacontenttype":"application/json","data":{"msg":"hello"}}`, string(body))
+
+	is.Equal(map[string]string{
+		"ce_specversion": "1.0",
+		"ce_id":          "42",
+		"ce_source":      "/my%20source",
+		"ce_type":        "slog.record.info",
+		"ce_time":        "2024-01-02T03:04:05Z",
+	}, CloudEventBinaryHeaders(event, CloudEventsKafkaPrefix))
+
+	req, err := NewCloudEventHTTPRequest(context.Background(), "http://localhost/events", event, CloudEventBinary)
+	is.NoError(err)
+	is.Equal("application/json", req.Header.Get("Content-Type"))
+	is.Equal("42", req.Header.Get("ce-id"))
+	is.Equal("1.0", req.Header.Get("ce-specversion"))
+	data, _ := io.ReadAll(req.Body)
+	is.JSONEq(`{"msg":"hello"}`, string(data))
+
+	req, err = NewCloudEventHTTPRequest(context.Background(), "http://localhost/events", event, CloudEventStructured)
+	is.NoError(err)
+	is.Equal("application/cloudevents+json", req.Header.Get("Content-Type"))
+	is.Empty(req.Header.Get("ce-id"))
+	var decoded CloudEvent
+	data, _ = io.ReadAll(req.Body)
+	is.NoError(json.Unmarshal(data, &decoded))
+	is.Equal(event, decoded)
+}
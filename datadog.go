@@ -0,0 +1,217 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const datadogMaxTagLength = 200
+
+type DatadogTagRule struct {
+	// Path to the attribute: groups followed by the key.
+	Path []string
+	// Tag name; defaults to the last element of Path.
+	Name string
+}
+
+type DatadogOptions struct {
+	// Default values, overridden by the "service", "env" and "version" attributes.
+	Service string
+	Env     string
+	Version string
+
+	// Tags are static "key:value" tags added to every record.
+	Tags []string
+	// TagRules extract attributes into ddtags. Extracted attributes are removed.
+	TagRules []DatadogTagRule
+
+	// ErrorKeys defaults to "error" and "err".
+	ErrorKeys []string
+	// TraceIDPath and SpanIDPath default to "trace_id" and "span_id". OTel hex ids
+	// are converted to Datadog 64-bit decimal ids.
+	TraceIDPath []string
+	SpanIDPath  []string
+	// UserIDPath defaults to "user" > "id".
+	UserIDPath []string
+}
+
+// DatadogStatus maps a slog.Level to a Datadog log status, following DatadogLevels.
+func DatadogStatus(level slog.Level) string {
+	return DatadogLevels.Map(level)
+}
+
+// RecordToDatadog builds a Datadog log payload, with reserved attributes at the
+// top level and tags in "ddtags". attrs is the full attribute set, usually built
+// with AppendRecordAttrsToAttrs.
+func RecordToDatadog(record *slog.Record, attrs []slog.Attr, opts DatadogOptions) map[string]any {
+	errorKeys := opts.ErrorKeys
+	if len(errorKeys) == 0 {
+		errorKeys = []string{"error", "err"}
+	}
+
+	attrs, err := ExtractError(attrs, errorKeys...)
+
+	reserved := map[string]any{
+		"service": opts.Service,
+		"env":     opts.Env,
+		"version": opts.Version,
+	}
+
+	for _, key := range []string{"service", "env", "version"} {
+		var value string
+		if value, attrs = extractAttrStringByPath(attrs, []string{key}); value != "" {
+			reserved[key] = value
+		}
+	}
+
+	var value string
+	if value, attrs = extractAttrStringByPath(attrs, defaultPath(opts.TraceIDPath, "trace_id")); value != "" {
+		reserved["dd.trace_id"] = DatadogID(value)
+	}
+	if value, attrs = extractAttrStringByPath(attrs, defaultPath(opts.SpanIDPath, "span_id")); value != "" {
+		reserved["dd.span_id"] = DatadogID(value)
+	}
+	if value, attrs = extractAttrStringByPath(attrs, defaultPath(opts.UserIDPath, "user", "id")); value != "" {
+		reserved["usr.id"] = value
+	}
+
+	tags := make([]string, 0, len(opts.Tags)+len(opts.TagRules))
+	for _, tag := range opts.Tags {
+		if tag = DatadogNormalizeTag(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	for _, rule := range opts.TagRules {
+		if len(rule.Path) == 0 {
+			continue
+		}
+		if value, attrs = extractAttrStringByPath(attrs, rule.Path); value == "" {
+			continue
+		}
+
+		name := rule.Name
+		if name == "" {
+			name = rule.Path[len(rule.Path)-1]
+		}
+
+		if tag := DatadogNormalizeTag(name + ":" + value); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	output := AttrsToMap(attrs...)
+	for k, v := range reserved {
+		if v != "" {
+			output[k] = v
+		}
+	}
+
+	if err != nil {
+		for k, v := range datadogError(err) {
+			output[k] = v
+		}
+	}
+
+	output["message"] = record.Message
+	output["status"] = DatadogStatus(record.Level)
+	if !record.Time.IsZero() {
+		output["timestamp"] = record.Time.UnixMilli()
+	}
+	if len(tags) > 0 {
+		output["ddtags"] = strings.Join(tags, ",")
+	}
+
+	return output
+}
+
+func datadogError(err error) map[string]any {
+	output := map[string]any{
+		"error.message": err.Error(),
+	}
+
+	switch v := FormatError(err).(type) {
+	case map[string]any:
+		if kind, ok := v["kind"]; ok && kind != nil {
+			output["error.kind"] = kind
+		}
+		if stack, ok := v["stack"]; ok && stack != nil {
+			output["error.stack"] = stack
+		}
+	case slog.Value:
+		if v = v.Resolve(); v.Kind() == slog.KindGroup {
+			for k, v := range AttrsToMap(v.Group()...) {
+				output["error."+k] = v
+			}
+		}
+	}
+
+	return output
+}
+
+// DatadogID converts an OpenTelemetry trace or span id (32 or 16 hex chars) to
+// the decimal 64-bit form used by Datadog, keeping the lower 64 bits. 16 chars
+// ids made of digits only are already Datadog ids. Other values are returned
+// unchanged.
+func DatadogID(id string) string {
+	if len(id) != 32 && (len(id) != 16 || strings.Trim(id, "0123456789") == "") {
+		return id
+	}
+
+	n, err := strconv.ParseUint(id[len(id)-16:], 16, 64)
+	if err != nil {
+		return id
+	}
+
+	return strconv.FormatUint(n, 10)
+}
+
+// DatadogNormalizeTag applies the Datadog tag rules: lowercase, starting with a
+// letter, made of alphanumerics, "_", "-", ":", "." and "/", at most 200 chars.
+func DatadogNormalizeTag(tag string) string {
+	var sb strings.Builder
+
+	lastUnderscore := false
+	for _, r := range strings.ToLower(tag) {
+		if sb.Len() == 0 && !unicode.IsLetter(r) {
+			continue
+		}
+		if sb.Len()+utf8.RuneLen(r) > datadogMaxTagLength {
+			break
+		}
+
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == ':', r == '.', r == '/':
+			sb.WriteRune(r)
+			lastUnderscore = false
+		default:
+			if !lastUnderscore {
+				sb.WriteByte('_')
+				lastUnderscore = true
+			}
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "_")
+}
+
+func extractAttrStringByPath(attrs []slog.Attr, path []string) (string, []slog.Attr) {
+	groups, key := path[:len(path)-1], path[len(path)-1]
+
+	attr, ok := FindAttrByGroupAndKey(attrs, groups, key)
+	if !ok || attr.Value.Resolve().Kind() == slog.KindGroup {
+		return "", attrs
+	}
+
+	return ValueToString(attr.Value), removeAttrByPath(attrs, groups, key)
+}
+
+func defaultPath(path []string, fallback ...string) []string {
+	if len(path) == 0 {
+		return fallback
+	}
+
+	return path
+}
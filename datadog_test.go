@@ -0,0 +1,96 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatadogStatus(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("debug", DatadogStatus(slog.LevelDebug))
+	is.Equal("info", DatadogStatus(slog.LevelInfo))
+	is.Equal("notice", DatadogStatus(slog.LevelInfo+2))
+	is.Equal("warning", DatadogStatus(slog.LevelWarn))
+	is.Equal("error", DatadogStatus(slog.LevelError))
+	is.Equal("critical", DatadogStatus(slog.LevelError+4))
+	is.Equal("emergency", DatadogStatus(slog.LevelError+20))
+	is.Equal("notice", DatadogStatus(slog.LevelInfo+1))
+	is.Equal("error", DatadogStatus(slog.LevelWarn+2))
+	is.Equal("critical", DatadogStatus(slog.LevelError+1))
+}
+
+func TestRecordToDatadog(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	now := time.Unix(1700000000, 0)
+	record := slog.NewRecord(now, slog.LevelError, "boom", 0)
+	attrs := []slog.Attr{
+		slog.String("env", "prod"),
+		slog.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+		slog.String("span_id", "00f067aa0ba902b7"),
+		slog.Group("user", slog.String("id", "u-1"), slog.String("email", "a@b.c")),
+		slog.Group("http", slog.String("route", "/users/:id")),
+		slog.Any("error", assert.AnError),
+		slog.Int("count", 3),
+	}
+
+	output := RecordToDatadog(&record, attrs, DatadogOptions{
+		Service:  "api",
+		Env:      "staging",
+		Tags:     []string{"Team:Core"},
+		TagRules: []DatadogTagRule{{Path: []string{"http", "route"}}, {Path: []string{"missing"}}},
+	})
+
+	is.Equal(map[string]any{
+		"service":       "api",
+		"env":           "prod",
+		"dd.trace_id":   "11803532876627986230",
+		"dd.span_id":    "67667974448284343",
+		"usr.id":        "u-1",
+		"user":          map[string]any{"email": "a@b.c"},
+		"count":         int64(3),
+		"error.message": assert.AnError.Error(),
+		"error.kind":    "*errors.errorString",
+		"message":       "boom",
+		"status":        "error",
+		"timestamp":     now.UnixMilli(),
+		"ddtags":        "team:core,route:/users/:id",
+	}, output)
+}
+
+func TestDatadogID(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("11803532876627986230", DatadogID("4bf92f3577b34da6a3ce929d0e0e4736"))
+	is.Equal("11803532876627986230", DatadogID("a3ce929d0e0e4736"))
+	is.Equal("12345", DatadogID("12345"))
+	is.Equal("1234567890123456", DatadogID("1234567890123456"))
+	is.Equal("1", DatadogID("00000000000000000000000000000001"))
+	is.Equal("zzzzzzzzzzzzzzzz", DatadogID("zzzzzzzzzzzzzzzz"))
+}
+
+func TestDatadogNormalizeTag(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("env:prod", DatadogNormalizeTag("Env:Prod"))
+	is.Equal("region:us-east-1", DatadogNormalizeTag("region:us-east-1"))
+	is.Equal("a_b", DatadogNormalizeTag("a  &&b"))
+	is.Equal("abc", DatadogNormalizeTag("123abc"))
+	is.Equal("key", DatadogNormalizeTag("key!!"))
+	is.Equal("", DatadogNormalizeTag("!!!"))
+	is.Len(DatadogNormalizeTag(string(make([]byte, 300))+"a"), 1)
+
+	long := ""
+	for i := 0; i < 300; i++ {
+		long += "a"
+	}
+	is.Len(DatadogNormalizeTag(long), 200)
+}
@@ -0,0 +1,280 @@
+package slogcommon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+type JSONNumberMode int
+
+const (
+	// JSONNumberFloat64 decodes every number as float64, like encoding/json.
+	JSONNumberFloat64 JSONNumberMode = iota
+	// JSONNumberInt64 decodes integral numbers as int64 (or uint64 when too large),
+	// and other numbers as float64.
+	JSONNumberInt64
+	// JSONNumberRaw keeps numbers as json.Number.
+	JSONNumberRaw
+)
+
+type JSONRecordDecoderOptions struct {
+	// TimeKey, LevelKey and MessageKey default to the slog.JSONHandler keys.
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+
+	// Levels maps custom level names (e.g. "AUDIT") to levels, on top of
+	// DefaultLevelRegistry. Names are matched case-insensitively and accept offsets
+	// ("AUDIT+1").
+	Levels map[string]slog.Level
+
+	Numbers JSONNumberMode
+}
+
+var ErrJSONRecordNotObject = errors.New("slog json: record is not an object")
+var ErrJSONRecordTrailingData = errors.New("slog json: trailing data after record")
+
+// DecodeJSONRecord parses a line written by slog.JSONHandler (or an AttrsToMap
+// output) back into a record. Attribute order is preserved and nested objects
+// become groups.
+func DecodeJSONRecord(line []byte, opts JSONRecordDecoderOptions) (slog.Record, error) {
+	levels, err := jsonRecordLevels(opts)
+	if err != nil {
+		return slog.Record{}, err
+	}
+
+	return decodeJSONRecord(line, opts, levels)
+}
+
+// jsonRecordLevels builds the registry of opts.Levels.
+func jsonRecordLevels(opts JSONRecordDecoderOptions) (*LevelRegistry, error) {
+	if len(opts.Levels) == 0 {
+		return DefaultLevelRegistry, nil
+	}
+
+	return DefaultLevelRegistry.With(opts.Levels)
+}
+
+func decodeJSONRecord(line []byte, opts JSONRecordDecoderOptions, levels *LevelRegistry) (slog.Record, error) {
+	timeKey := opts.TimeKey
+	if timeKey == "" {
+		timeKey = slog.TimeKey
+	}
+	levelKey := opts.LevelKey
+	if levelKey == "" {
+		levelKey = slog.LevelKey
+	}
+	messageKey := opts.MessageKey
+	if messageKey == "" {
+		messageKey = slog.MessageKey
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return slog.Record{}, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return slog.Record{}, ErrJSONRecordNotObject
+	}
+
+	attrs, err := decodeJSONObject(dec, opts.Numbers)
+	if err != nil {
+		return slog.Record{}, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return slog.Record{}, ErrJSONRecordTrailingData
+	}
+
+	var t time.Time
+	var level slog.Level
+	var msg string
+	output := make([]slog.Attr, 0, len(attrs))
+
+	for _, attr := range attrs {
+		switch attr.Key {
+		case timeKey:
+			if t, err = parseJSONRecordTime(attr.Value); err != nil {
+				return slog.Record{}, err
+			}
+		case levelKey:
+			if level, err = parseJSONRecordLevel(attr.Value, levels); err != nil {
+				return slog.Record{}, err
+			}
+		case messageKey:
+			msg = ValueToString(attr.Value)
+		default:
+			output = append(output, attr)
+		}
+	}
+
+	record := slog.NewRecord(t, level, msg, 0)
+	record.AddAttrs(output...)
+
+	return record, nil
+}
+
+// DecodeJSONRecords reads JSON lines and calls fn for each record. Empty lines are
+// skipped. It stops at the first error, including the one returned by fn.
+func DecodeJSONRecords(r io.Reader, opts JSONRecordDecoderOptions, fn func(slog.Record) error) error {
+	levels, err := jsonRecordLevels(opts)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		record, err := decodeJSONRecord(line, opts, levels)
+		if err != nil {
+			return fmt.Errorf("slog json: line %d: %w", lineNumber, err)
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func decodeJSONObject(dec *json.Decoder, mode JSONNumberMode) ([]slog.Attr, error) {
+	var attrs []slog.Attr
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("slog json: unexpected token %v", tok)
+		}
+
+		value, err := decodeJSONValue(dec, mode)
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, slog.Attr{Key: key, Value: value})
+	}
+
+	// closing '}'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return attrs, nil
+}
+
+func decodeJSONValue(dec *json.Decoder, mode JSONNumberMode) (slog.Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+
+	switch x := tok.(type) {
+	case json.Delim:
+		if x == '{' {
+			attrs, err := decodeJSONObject(dec, mode)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			return slog.GroupValue(attrs...), nil
+		}
+
+		values := []any{}
+		for dec.More() {
+			v, err := decodeJSONValue(dec, mode)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			values = append(values, jsonValueToAny(v))
+		}
+		if _, err := dec.Token(); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.AnyValue(values), nil
+	case json.Number:
+		return decodeJSONNumber(x, mode), nil
+	case string:
+		return slog.StringValue(x), nil
+	case bool:
+		return slog.BoolValue(x), nil
+	default:
+		return slog.AnyValue(nil), nil
+	}
+}
+
+func decodeJSONNumber(n json.Number, mode JSONNumberMode) slog.Value {
+	switch mode {
+	case JSONNumberRaw:
+		return slog.AnyValue(n)
+	case JSONNumberInt64:
+		if i, err := n.Int64(); err == nil {
+			return slog.Int64Value(i)
+		}
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+			return slog.Uint64Value(u)
+		}
+	}
+
+	// out of range numbers are decoded as ±Inf
+	f, _ := n.Float64()
+	return slog.Float64Value(f)
+}
+
+// jsonValueToAny converts array items so that nested objects are plain maps,
+// like encoding/json does.
+func jsonValueToAny(v slog.Value) any {
+	if v.Kind() == slog.KindGroup {
+		return AttrsToMap(v.Group()...)
+	}
+
+	return v.Any()
+}
+
+func parseJSONRecordTime(v slog.Value) (time.Time, error) {
+	if v.Kind() != slog.KindString {
+		return time.Time{}, fmt.Errorf("slog json: invalid time %v", v.Any())
+	}
+
+	return time.Parse(time.RFC3339Nano, v.String())
+}
+
+func parseJSONRecordLevel(v slog.Value, levels *LevelRegistry) (slog.Level, error) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return slog.Level(v.Int64()), nil
+	case slog.KindFloat64:
+		return slog.Level(v.Float64()), nil
+	case slog.KindString:
+		return levels.ParseLevel(v.String())
+	default:
+		if n, ok := v.Any().(json.Number); ok {
+			i, err := n.Int64()
+			return slog.Level(i), err
+		}
+		return 0, fmt.Errorf("slog json: invalid level %v", v.Any())
+	}
+}
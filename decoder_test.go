@@ -0,0 +1,144 @@
+package slogcommon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordAttrs(r slog.Record) []slog.Attr {
+	attrs := []slog.Attr{}
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
+
+func TestDecodeJSONRecord(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger.
+		WithGroup("http").
+		With("method", "GET").
+		Log(context.Background(), slog.LevelInfo+2, "hello", "status", 200, "latency", 1.5, "tags", []string{"a"}, "ok", true, "none", nil)
+
+	record, err := DecodeJSONRecord(buf.Bytes(), JSONRecordDecoderOptions{Numbers: JSONNumberInt64})
+	is.NoError(err)
+	is.WithinDuration(time.Now(), record.Time, time.Minute)
+	is.Equal(slog.LevelInfo+2, record.Level)
+	is.Equal("hello", record.Message)
+	is.Equal([]slog.Attr{
+		slog.Group("http",
+			slog.String("method", "GET"),
+			slog.Int64("status", 200),
+			slog.Float64("latency", 1.5),
+			slog.Any("tags", []any{"a"}),
+			slog.Bool("ok", true),
+			slog.Any("none", nil),
+		),
+	}, recordAttrs(record))
+
+	// default float mode
+	record, err = DecodeJSONRecord([]byte(`{"level":"warn","msg":"x","n":1,"big":18446744073709551615}`), JSONRecordDecoderOptions{})
+	is.NoError(err)
+	is.True(record.Time.IsZero())
+	is.Equal(slog.LevelWarn, record.Level)
+	is.Equal([]slog.Attr{slog.Float64("n", 1), slog.Float64("big", 18446744073709551615)}, recordAttrs(record))
+
+	record, err = DecodeJSONRecord([]byte(`{"big":18446744073709551615,"list":[{"a":1}]}`), JSONRecordDecoderOptions{Numbers: JSONNumberInt64})
+	is.NoError(err)
+	is.Equal([]slog.Attr{slog.Uint64("big", 18446744073709551615), slog.Any("list", []any{map[string]any{"a": int64(1)}})}, recordAttrs(record))
+
+	record, err = DecodeJSONRecord([]byte(`{"n":1.50}`), JSONRecordDecoderOptions{Numbers: JSONNumberRaw})
+	is.NoError(err)
+	is.Equal([]slog.Attr{slog.Any("n", json.Number("1.50"))}, recordAttrs(record))
+}
+
+func TestDecodeJSONRecordCustomKeysAndLevels(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	opts := JSONRecordDecoderOptions{
+		TimeKey:    "ts",
+		LevelKey:   "severity",
+		MessageKey: "message",
+		Levels:     map[string]slog.Level{"AUDIT": 10},
+	}
+
+	record, err := DecodeJSONRecord([]byte(`{"ts":"2024-01-02T03:04:05.123Z","severity":"audit+1","message":"m","time":"kept"}`), opts)
+	is.NoError(err)
+	is.Equal(time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.UTC), record.Time)
+	is.Equal(slog.Level(11), record.Level)
+	is.Equal("m", record.Message)
+	is.Equal([]slog.Attr{slog.String("time", "kept")}, recordAttrs(record))
+
+	record, err = DecodeJSONRecord([]byte(`{"severity":12}`), opts)
+	is.NoError(err)
+	is.Equal(slog.Level(12), record.Level)
+
+	record, err = DecodeJSONRecord([]byte(`{"severity":"Audit"}`), opts)
+	is.NoError(err)
+	is.Equal(slog.Level(10), record.Level)
+
+	_, err = DecodeJSONRecord([]byte(`{"severity":"unknown"}`), opts)
+	is.Error(err)
+	_, err = DecodeJSONRecord([]byte(`{"severity":"info"}`), JSONRecordDecoderOptions{LevelKey: "severity", Levels: map[string]slog.Level{"a+b": 1}})
+	is.Error(err)
+	_, err = DecodeJSONRecord([]byte(`{"ts":"yesterday"}`), opts)
+	is.Error(err)
+	_, err = DecodeJSONRecord([]byte(`[1]`), opts)
+	is.ErrorIs(err, ErrJSONRecordNotObject)
+	_, err = DecodeJSONRecord([]byte(`{"a":`), opts)
+	is.Error(err)
+	_, err = DecodeJSONRecord([]byte(`{"msg":"a"} garbage`), opts)
+	is.ErrorIs(err, ErrJSONRecordTrailingData)
+	_, err = DecodeJSONRecord([]byte(`{"msg":"a"}{"msg":"b"}`), opts)
+	is.ErrorIs(err, ErrJSONRecordTrailingData)
+	_, err = DecodeJSONRecord([]byte("{\"msg\":\"a\"} \n"), opts)
+	is.NoError(err)
+}
+
+func TestDecodeJSONRecords(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	input := "{\"msg\":\"a\"}\n\n{\"msg\":\"b\"}\n"
+
+	var messages []string
+	err := DecodeJSONRecords(strings.NewReader(input), JSONRecordDecoderOptions{}, func(r slog.Record) error {
+		messages = append(messages, r.Message)
+		return nil
+	})
+	is.NoError(err)
+	is.Equal([]string{"a", "b"}, messages)
+
+	err = DecodeJSONRecords(strings.NewReader("{\"msg\":\"a\"}\nnot json\n"), JSONRecordDecoderOptions{}, func(r slog.Record) error { return nil })
+	is.ErrorContains(err, "line 2")
+
+	err = DecodeJSONRecords(strings.NewReader(input), JSONRecordDecoderOptions{}, func(r slog.Record) error { return assert.AnError })
+	is.True(errors.Is(err, assert.AnError))
+
+	// custom levels
+	var levels []slog.Level
+	opts := JSONRecordDecoderOptions{Levels: map[string]slog.Level{"AUDIT": 10}}
+	err = DecodeJSONRecords(strings.NewReader("{\"level\":\"AUDIT\"}\n{\"level\":\"INFO\"}\n"), opts, func(r slog.Record) error {
+		levels = append(levels, r.Level)
+		return nil
+	})
+	is.NoError(err)
+	is.Equal([]slog.Level{10, slog.LevelInfo}, levels)
+
+	err = DecodeJSONRecords(strings.NewReader(input), JSONRecordDecoderOptions{Levels: map[string]slog.Level{"a+b": 1}}, func(r slog.Record) error { return nil })
+	is.Error(err)
+}
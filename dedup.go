@@ -0,0 +1,201 @@
+package slogcommon
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+type DeduplicatorOptions struct {
+	// Window is the suppression period following the first record of a fingerprint.
+	Window time.Duration
+	// Paths of the attributes included in the fingerprint, along with the level and
+	// the message, eg: {"error"}, {"http", "route"}.
+	Paths [][]string
+	// Fingerprint replaces the level, message and Paths based fingerprint
+	// (optional), eg: Fingerprint with FingerprintOptions.
+	Fingerprint func(record *slog.Record, attrs []slog.Attr) string
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// Deduplicator suppresses repeated records: the first record of a fingerprint is
+// allowed, the following ones are counted until the window closes. A summary
+// record is then available through Flush, eg: "timeout (repeated 532 times in 1m0s)".
+//
+// Expired windows are closed by Allow at most once per window, so that memory
+// stays bounded by the fingerprints seen during the last two windows, plus the
+// summaries waiting for Flush.
+type Deduplicator struct {
+	opts DeduplicatorOptions
+
+	mu        sync.Mutex
+	entries   map[uint64]*dedupEntry
+	closed    []slog.Record
+	lastSweep time.Time
+}
+
+type dedupEntry struct {
+	record     slog.Record
+	first      time.Time
+	suppressed uint64
+}
+
+func NewDeduplicator(opts DeduplicatorOptions) *Deduplicator {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return &Deduplicator{
+		opts:    opts,
+		entries: map[uint64]*dedupEntry{},
+	}
+}
+
+// Allow reports whether a record must be emitted. attrs is the full attribute
+// set, usually built with AppendRecordAttrsToAttrs.
+func (d *Deduplicator) Allow(record *slog.Record, attrs []slog.Attr) bool {
+	key := d.fingerprint(record, attrs)
+	now := d.opts.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.lastSweep) >= d.opts.Window {
+		d.sweepLocked(now)
+	}
+
+	if entry, ok := d.entries[key]; ok {
+		if now.Sub(entry.first) < d.opts.Window {
+			entry.suppressed++
+			return false
+		}
+
+		d.closeLocked(key, entry, now)
+	}
+
+	d.entries[key] = &dedupEntry{
+		record: record.Clone(),
+		first:  now,
+	}
+
+	return true
+}
+
+// Flush returns the summaries of closed windows, oldest first.
+func (d *Deduplicator) Flush() []slog.Record {
+	return d.flush(false)
+}
+
+// FlushAll returns the summaries of all windows, closing them. It is meant to be
+// called on shutdown.
+func (d *Deduplicator) FlushAll() []slog.Record {
+	return d.flush(true)
+}
+
+// Report calls fn with summaries every interval. A non-positive interval
+// defaults to 1s. The returned function stops reporting.
+func (d *Deduplicator) Report(interval time.Duration, fn func(summary slog.Record)) (stop func()) {
+	return runEvery(interval, func() {
+		for _, summary := range d.Flush() {
+			fn(summary)
+		}
+	})
+}
+
+func (d *Deduplicator) flush(all bool) []slog.Record {
+	now := d.opts.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if all {
+		for key, entry := range d.entries {
+			d.closeLocked(key, entry, now)
+		}
+	} else {
+		d.sweepLocked(now)
+	}
+
+	summaries := d.closed
+	d.closed = nil
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Time.Before(summaries[j].Time)
+	})
+
+	return summaries
+}
+
+// sweepLocked closes expired windows.
+func (d *Deduplicator) sweepLocked(now time.Time) {
+	d.lastSweep = now
+
+	for key, entry := range d.entries {
+		if now.Sub(entry.first) >= d.opts.Window {
+			d.closeLocked(key, entry, now)
+		}
+	}
+}
+
+func (d *Deduplicator) closeLocked(key uint64, entry *dedupEntry, now time.Time) {
+	delete(d.entries, key)
+
+	if entry.suppressed == 0 {
+		return
+	}
+
+	// the window may have closed long before
+	end := entry.first.Add(d.opts.Window)
+	if now.Before(end) {
+		end = now
+	}
+
+	summary := slog.NewRecord(
+		end,
+		entry.record.Level,
+		fmt.Sprintf("%s (repeated %d times in %s)", entry.record.Message, entry.suppressed, end.Sub(entry.first)),
+		entry.record.PC,
+	)
+	entry.record.Attrs(func(attr slog.Attr) bool {
+		summary.AddAttrs(attr)
+		return true
+	})
+	summary.AddAttrs(
+		slog.Uint64("repeated", entry.suppressed),
+		slog.Time("first", entry.first),
+	)
+
+	d.closed = append(d.closed, summary)
+}
+
+func (d *Deduplicator) fingerprint(record *slog.Record, attrs []slog.Attr) uint64 {
+	h := fnv.New64a()
+
+	if d.opts.Fingerprint != nil {
+		_, _ = h.Write([]byte(d.opts.Fingerprint(record, attrs)))
+		return h.Sum64()
+	}
+
+	_, _ = h.Write([]byte(record.Level.String()))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(record.Message))
+
+	for _, path := range d.opts.Paths {
+		if len(path) == 0 {
+			continue
+		}
+
+		// a missing attribute differs from an empty one
+		_, _ = h.Write([]byte{0})
+		if attr, ok := FindAttribute(attrs, path[:len(path)-1], path[len(path)-1]); ok {
+			_, _ = h.Write([]byte{1})
+			_, _ = h.Write([]byte(ValueToString(attr.Value)))
+		}
+	}
+
+	return h.Sum64()
+}
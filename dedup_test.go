@@ -0,0 +1,121 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduplicator(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	d := NewDeduplicator(DeduplicatorOptions{
+		Window: time.Minute,
+		Paths:  [][]string{{"error"}, {"http", "route"}},
+		Now:    func() time.Time { return now },
+	})
+
+	record := slog.NewRecord(now, slog.LevelError, "timeout", 0)
+	record.AddAttrs(slog.String("error", "i/o timeout"))
+	attrs := []slog.Attr{slog.String("error", "i/o timeout"), slog.Group("http", slog.String("route", "/a")), slog.Int("latency", 1)}
+
+	is.True(d.Allow(&record, attrs))
+	for i := 0; i < 532; i++ {
+		now = now.Add(100 * time.Millisecond)
+		is.False(d.Allow(&record, attrs))
+	}
+
+	// different fingerprints
+	is.True(d.Allow(&record, []slog.Attr{slog.String("error", "i/o timeout"), slog.Group("http", slog.String("route", "/b"))}))
+	is.True(d.Allow(&record, []slog.Attr{slog.String("error", "EOF"), slog.Group("http", slog.String("route", "/a"))}))
+	warn := record.Clone()
+	warn.Level = slog.LevelWarn
+	is.True(d.Allow(&warn, attrs))
+	// unselected attributes are ignored
+	is.False(d.Allow(&record, []slog.Attr{slog.String("error", "i/o timeout"), slog.Group("http", slog.String("route", "/a")), slog.Int("latency", 2)}))
+
+	is.Empty(d.Flush())
+
+	now = start.Add(61 * time.Second)
+	summaries := d.Flush()
+	is.Len(summaries, 1)
+	is.Equal("timeout (repeated 533 times in 1m0s)", summaries[0].Message)
+	is.Equal(slog.LevelError, summaries[0].Level)
+	is.Equal(start.Add(time.Minute), summaries[0].Time)
+	is.Equal(
+		map[string]any{"error": "i/o timeout", "repeated": uint64(533), "first": start},
+		RecordToAttrsMap(summaries[0]),
+	)
+
+	// a new window starts
+	is.True(d.Allow(&record, attrs))
+	is.False(d.Allow(&record, attrs))
+	is.Empty(d.Flush())
+
+	// window closed by a new record
+	now = now.Add(2 * time.Minute)
+	is.True(d.Allow(&record, attrs))
+	summaries = d.Flush()
+	is.Len(summaries, 1)
+	is.Equal("timeout (repeated 1 times in 1m0s)", summaries[0].Message)
+
+	// on shutdown
+	now = now.Add(time.Second)
+	is.False(d.Allow(&record, attrs))
+	summaries = d.FlushAll()
+	is.Len(summaries, 1)
+	is.Equal("timeout (repeated 1 times in 1s)", summaries[0].Message)
+	is.Empty(d.FlushAll())
+
+	// a missing attribute differs from an empty one
+	is.True(d.Allow(&record, []slog.Attr{slog.String("error", "")}))
+	is.True(d.Allow(&record, nil))
+	is.False(d.Allow(&record, nil))
+
+	// expired windows are evicted by Allow
+	for i := 0; i < 100; i++ {
+		is.True(d.Allow(&record, []slog.Attr{slog.Int("error", i)}))
+	}
+	now = now.Add(time.Minute)
+	is.True(d.Allow(&record, attrs))
+	d.mu.Lock()
+	is.Len(d.entries, 1)
+	d.mu.Unlock()
+	is.Len(d.Flush(), 1)
+}
+
+func TestDeduplicatorReport(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	d := NewDeduplicator(DeduplicatorOptions{Window: 5 * time.Millisecond})
+	record := slog.NewRecord(time.Now(), slog.LevelError, "boom", 0)
+	is.True(d.Allow(&record, nil))
+	is.False(d.Allow(&record, nil))
+
+	var mu sync.Mutex
+	summaries := []slog.Record{}
+	stop := d.Report(time.Millisecond, func(summary slog.Record) {
+		mu.Lock()
+		defer mu.Unlock()
+		summaries = append(summaries, summary)
+	})
+	defer stop()
+
+	is.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(summaries) == 1
+	}, time.Second, time.Millisecond)
+
+	stop()
+	mu.Lock()
+	is.Contains(summaries[0].Message, "boom (repeated 1 times in ")
+	mu.Unlock()
+}
@@ -0,0 +1,111 @@
+package slogcommon
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+)
+
+// AttrLevelRule sets the minimum level of loggers bound to an attribute, eg:
+// component=db.
+type AttrLevelRule struct {
+	Groups []string
+	Key    string
+	// Value is compared to ValueToString of the attribute. Empty matches any value.
+	Value string
+	Level slog.Level
+}
+
+// AttrLevelFilter decides enabled-ness from the attributes bound with WithAttrs
+// and WithGroup. The first matching rule wins, otherwise the fallback level
+// applies. Rules can be replaced at runtime.
+type AttrLevelFilter struct {
+	fallback slog.Leveler
+	rules    atomic.Pointer[[]AttrLevelRule]
+}
+
+// NewAttrLevelFilter returns a filter. fallback defaults to slog.LevelInfo.
+func NewAttrLevelFilter(fallback slog.Leveler, rules ...AttrLevelRule) *AttrLevelFilter {
+	if fallback == nil {
+		fallback = slog.LevelInfo
+	}
+
+	f := &AttrLevelFilter{fallback: fallback}
+	f.SetRules(rules...)
+	return f
+}
+
+func (f *AttrLevelFilter) SetRules(rules ...AttrLevelRule) {
+	rules = slices.Clone(rules)
+	f.rules.Store(&rules)
+}
+
+func (f *AttrLevelFilter) Rules() []AttrLevelRule {
+	return slices.Clone(*f.rules.Load())
+}
+
+// Level returns the minimum level for the bound attributes.
+func (f *AttrLevelFilter) Level(attrs []slog.Attr) slog.Level {
+	for _, rule := range *f.rules.Load() {
+		attr, ok := FindAttrByGroupAndKey(attrs, rule.Groups, rule.Key)
+		if ok && (rule.Value == "" || ValueToString(attr.Value) == rule.Value) {
+			return rule.Level
+		}
+	}
+
+	return f.fallback.Level()
+}
+
+func (f *AttrLevelFilter) Enabled(attrs []slog.Attr, level slog.Level) bool {
+	return level >= f.Level(attrs)
+}
+
+// NewAttrLevelFilterHandler wraps a handler with an AttrLevelFilter. Records must
+// also be enabled by the wrapped handler, which usually has a low level.
+func NewAttrLevelFilterHandler(next slog.Handler, filter *AttrLevelFilter) slog.Handler {
+	return &attrLevelFilterHandler{
+		next:   next,
+		filter: filter,
+		attrs:  []slog.Attr{},
+		groups: []string{},
+	}
+}
+
+type attrLevelFilterHandler struct {
+	next   slog.Handler
+	filter *AttrLevelFilter
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *attrLevelFilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.filter.Enabled(h.attrs, level) && h.next.Enabled(ctx, level)
+}
+
+func (h *attrLevelFilterHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.next.Handle(ctx, record)
+}
+
+func (h *attrLevelFilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &attrLevelFilterHandler{
+		next:   h.next.WithAttrs(attrs),
+		filter: h.filter,
+		attrs:  AppendAttrsToGroup(h.groups, h.attrs, attrs...),
+		groups: h.groups,
+	}
+}
+
+func (h *attrLevelFilterHandler) WithGroup(name string) slog.Handler {
+	// https://cs.opensource.google/go/x/exp/+/46b07846:slog/handler.go;l=247
+	if name == "" {
+		return h
+	}
+
+	return &attrLevelFilterHandler{
+		next:   h.next.WithGroup(name),
+		filter: h.filter,
+		attrs:  h.attrs,
+		groups: append(slices.Clone(h.groups), name),
+	}
+}
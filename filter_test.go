@@ -0,0 +1,68 @@
+package slogcommon
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrLevelFilter(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	f := NewAttrLevelFilter(slog.LevelInfo,
+		AttrLevelRule{Key: "component", Value: "db", Level: slog.LevelDebug},
+		AttrLevelRule{Groups: []string{"http"}, Key: "route", Level: slog.LevelWarn},
+	)
+
+	is.Equal(slog.LevelInfo, f.Level(nil))
+	is.Equal(slog.LevelDebug, f.Level([]slog.Attr{slog.String("component", "db")}))
+	is.Equal(slog.LevelInfo, f.Level([]slog.Attr{slog.String("component", "http")}))
+	is.Equal(slog.LevelWarn, f.Level([]slog.Attr{slog.Group("http", slog.String("route", "/"))}))
+	is.Equal(slog.LevelInfo, f.Level([]slog.Attr{slog.String("route", "/")}))
+	is.True(f.Enabled([]slog.Attr{slog.String("component", "db")}, slog.LevelDebug))
+	is.False(f.Enabled(nil, slog.LevelDebug))
+
+	// hot reload
+	f.SetRules(AttrLevelRule{Key: "component", Value: "db", Level: slog.LevelError})
+	is.Equal(slog.LevelError, f.Level([]slog.Attr{slog.String("component", "db")}))
+	is.Len(f.Rules(), 1)
+
+	// nil fallback
+	is.Equal(slog.LevelInfo, NewAttrLevelFilter(nil).Level(nil))
+}
+
+func TestAttrLevelFilterHandler(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	level := &slog.LevelVar{}
+	f := NewAttrLevelFilter(level, AttrLevelRule{Groups: []string{"svc"}, Key: "component", Value: "db", Level: slog.LevelDebug})
+
+	logger := slog.New(NewAttrLevelFilterHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), f))
+	db := logger.WithGroup("svc").With("component", "db")
+	api := logger.WithGroup("svc").With("component", "api")
+
+	db.Debug("db debug")
+	api.Debug("api debug")
+	api.Info("api info")
+	logger.WithGroup("").Debug("root debug")
+	is.Contains(buf.String(), `msg="db debug" svc.component=db`)
+	is.NotContains(buf.String(), "api debug")
+	is.Contains(buf.String(), `msg="api info" svc.component=api`)
+	is.NotContains(buf.String(), "root debug")
+
+	// rules and fallback changes apply to existing loggers
+	buf.Reset()
+	f.SetRules()
+	level.Set(slog.LevelWarn)
+	db.Debug("db debug")
+	api.Info("api info")
+	api.Warn("api warn")
+	is.NotContains(buf.String(), "db debug")
+	is.NotContains(buf.String(), "api info")
+	is.Contains(buf.String(), "api warn")
+}
@@ -0,0 +1,120 @@
+package slogcommon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultFingerprintIgnoreKeys lists volatile keys, skipped at any depth.
+var DefaultFingerprintIgnoreKeys = []string{
+	"time", "timestamp", "ts", "@timestamp",
+	"id", "uuid", "request_id", "trace_id", "span_id",
+	"duration", "latency", "elapsed",
+}
+
+var (
+	fingerprintUUID   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	fingerprintHex    = regexp.MustCompile(`\b(0[xX][0-9a-fA-F]+|[0-9a-fA-F]{12,})\b`)
+	fingerprintNumber = regexp.MustCompile(`[0-9]+(\.[0-9]+)?`)
+
+	// group "a" holding "b" and key "a.b" must not collide
+	fingerprintKeyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`, "=", `\=`)
+)
+
+type FingerprintOptions struct {
+	// IgnoreKeys are compared case-insensitively to keys at any depth. Defaults to
+	// DefaultFingerprintIgnoreKeys.
+	IgnoreKeys []string
+	// KeysOnly leaves attribute values out of the fingerprint.
+	KeysOnly bool
+	// NormalizeValues applies FingerprintNormalize to attribute values as well. By
+	// default, values are hashed as-is, so that status codes or ports still tell
+	// records apart.
+	NormalizeValues bool
+}
+
+// Fingerprint returns a stable hash of the level, the message and the attributes,
+// independent of attribute order. The message is normalized with
+// FingerprintNormalize. attrs is the full attribute set, usually built with
+// AppendRecordAttrsToAttrs.
+func Fingerprint(record *slog.Record, attrs []slog.Attr, opts FingerprintOptions) string {
+	ignore := opts.IgnoreKeys
+	if ignore == nil {
+		ignore = DefaultFingerprintIgnoreKeys
+	}
+
+	attrs, _ = SafeResolveAttrs(SafeResolveOptions{}, attrs...)
+	lines := appendFingerprintLines(nil, "", attrs, ignore, opts)
+	sort.Strings(lines)
+
+	h := sha256.New()
+	_, _ = h.Write([]byte(record.Level.String()))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(FingerprintNormalize(record.Message)))
+	for _, line := range lines {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(line))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// FingerprintNormalize replaces UUIDs, hexadecimal ids and numbers by placeholders:
+// "user 42 not found" becomes "user <n> not found".
+func FingerprintNormalize(s string) string {
+	s = fingerprintUUID.ReplaceAllString(s, "<uuid>")
+	s = fingerprintHex.ReplaceAllStringFunc(s, func(match string) string {
+		// plain numbers are handled below
+		if strings.Trim(match, "0123456789") == "" {
+			return match
+		}
+		return "<hex>"
+	})
+	return fingerprintNumber.ReplaceAllString(s, "<n>")
+}
+
+// appendFingerprintLines flattens attributes into "path=value" lines, with '.',
+// '=' and '\' escaped in keys. Empty group keys are inlined, like slog handlers do.
+func appendFingerprintLines(lines []string, prefix string, attrs []slog.Attr, ignore []string, opts FingerprintOptions) []string {
+	for _, attr := range attrs {
+		if isFingerprintIgnored(attr.Key, ignore) {
+			continue
+		}
+
+		path := prefix + fingerprintKeyEscaper.Replace(attr.Key)
+		v := attr.Value
+
+		if v.Kind() == slog.KindGroup {
+			if attr.Key != "" {
+				path += "."
+			}
+			lines = appendFingerprintLines(lines, path, v.Group(), ignore, opts)
+			continue
+		}
+
+		switch {
+		case opts.KeysOnly:
+			lines = append(lines, path)
+		case opts.NormalizeValues:
+			lines = append(lines, path+"="+FingerprintNormalize(ValueToString(v)))
+		default:
+			lines = append(lines, path+"="+ValueToString(v))
+		}
+	}
+
+	return lines
+}
+
+func isFingerprintIgnored(key string, ignore []string) bool {
+	for _, k := range ignore {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprintNormalize(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("user <n> not found", FingerprintNormalize("user 42 not found"))
+	is.Equal("took <n>ms", FingerprintNormalize("took 12.5ms"))
+	is.Equal("request <uuid> failed", FingerprintNormalize("request 123e4567-e89b-12d3-a456-426614174000 failed"))
+	is.Equal("object <hex> at <hex>", FingerprintNormalize("object 5f2b8c9e1a3d4f6b at 0xc000123abc"))
+	is.Equal("order <n>", FingerprintNormalize("order 123456789012345"))
+	is.Equal("connection refused", FingerprintNormalize("connection refused"))
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	record := slog.NewRecord(time.Now(), slog.LevelError, "user 42 not found", 0)
+	fp := Fingerprint(&record, []slog.Attr{
+		slog.String("error", "EOF"),
+		slog.Group("http", slog.String("route", "/users/:id"), slog.Int("status", 404)),
+		slog.String("request_id", "abc"),
+		slog.Time("time", time.Now()),
+	}, FingerprintOptions{})
+	is.Len(fp, 32)
+
+	// stable, order independent, volatile keys and numbers of the message ignored
+	other := slog.NewRecord(time.Now().Add(time.Hour), slog.LevelError, "user 43 not found", 0)
+	is.Equal(fp, Fingerprint(&other, []slog.Attr{
+		slog.Group("http", slog.Int("status", 404)),
+		slog.String("REQUEST_ID", "def"),
+		slog.Group("http", slog.String("route", "/users/:id")),
+		slog.String("error", "EOF"),
+	}, FingerprintOptions{}))
+
+	// inlined groups
+	is.Equal(fp, Fingerprint(&other, []slog.Attr{
+		slog.Group("", slog.String("error", "EOF")),
+		slog.Group("http", slog.String("route", "/users/:id"), slog.Int("status", 404)),
+	}, FingerprintOptions{}))
+
+	// level, message, keys and values matter
+	warn := record.Clone()
+	warn.Level = slog.LevelWarn
+	is.NotEqual(fp, Fingerprint(&warn, []slog.Attr{slog.String("error", "EOF"), slog.Group("http", slog.String("route", "/users/:id"), slog.Int("status", 404))}, FingerprintOptions{}))
+	is.NotEqual(fp, Fingerprint(&record, []slog.Attr{slog.String("error", "timeout"), slog.Group("http", slog.String("route", "/users/:id"), slog.Int("status", 404))}, FingerprintOptions{}))
+	is.NotEqual(fp, Fingerprint(&record, []slog.Attr{slog.String("error", "EOF")}, FingerprintOptions{}))
+	is.NotEqual(fp, Fingerprint(&record, []slog.Attr{slog.String("error", "EOF"), slog.Group("http", slog.String("route", "/users/:id"), slog.Int("status", 500))}, FingerprintOptions{}))
+	is.NotEqual(
+		Fingerprint(&record, []slog.Attr{slog.String("a.b", "c")}, FingerprintOptions{}),
+		Fingerprint(&record, []slog.Attr{slog.String("a", "b.c")}, FingerprintOptions{}),
+	)
+	is.NotEqual(
+		Fingerprint(&record, []slog.Attr{slog.Group("a", slog.String("b", "c"))}, FingerprintOptions{}),
+		Fingerprint(&record, []slog.Attr{slog.String("a.b", "c")}, FingerprintOptions{}),
+	)
+	is.NotEqual(
+		Fingerprint(&record, []slog.Attr{slog.String("a=b", "c")}, FingerprintOptions{}),
+		Fingerprint(&record, []slog.Attr{slog.String("a", "b=c")}, FingerprintOptions{}),
+	)
+
+	// options
+	is.Equal(
+		Fingerprint(&record, []slog.Attr{slog.String("error", "EOF")}, FingerprintOptions{KeysOnly: true}),
+		Fingerprint(&record, []slog.Attr{slog.String("error", "timeout")}, FingerprintOptions{KeysOnly: true}),
+	)
+	is.Equal(
+		Fingerprint(&record, []slog.Attr{slog.String("error", "user 42 not found")}, FingerprintOptions{NormalizeValues: true}),
+		Fingerprint(&record, []slog.Attr{slog.String("error", "user 43 not found")}, FingerprintOptions{NormalizeValues: true}),
+	)
+	is.NotEqual(
+		Fingerprint(&record, []slog.Attr{slog.Int("status", 404)}, FingerprintOptions{}),
+		Fingerprint(&record, []slog.Attr{slog.Int("status", 500)}, FingerprintOptions{}),
+	)
+	is.NotEqual(
+		Fingerprint(&record, []slog.Attr{slog.String("request_id", "1")}, FingerprintOptions{IgnoreKeys: []string{}}),
+		Fingerprint(&record, []slog.Attr{}, FingerprintOptions{IgnoreKeys: []string{}}),
+	)
+	is.Equal(
+		Fingerprint(&record, []slog.Attr{slog.String("error", "EOF"), slog.String("host", "a")}, FingerprintOptions{IgnoreKeys: []string{"host"}}),
+		Fingerprint(&record, []slog.Attr{slog.String("error", "EOF"), slog.String("host", "b")}, FingerprintOptions{IgnoreKeys: []string{"host"}}),
+	)
+}
+
+func TestDeduplicatorFingerprint(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	d := NewDeduplicator(DeduplicatorOptions{
+		Window: time.Hour,
+		Fingerprint: func(record *slog.Record, attrs []slog.Attr) string {
+			return Fingerprint(record, attrs, FingerprintOptions{})
+		},
+	})
+
+	a := slog.NewRecord(time.Now(), slog.LevelError, "retry 1 failed", 0)
+	b := slog.NewRecord(time.Now(), slog.LevelError, "retry 2 failed", 0)
+	is.True(d.Allow(&a, nil))
+	is.False(d.Allow(&b, nil))
+	is.Len(d.FlushAll(), 1)
+}
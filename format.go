@@ -0,0 +1,205 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TimeFormat int
+
+const (
+	// TimeFormatDefault keeps time.Time values, and renders strings with time.Time.String.
+	TimeFormatDefault TimeFormat = iota
+	TimeFormatRFC3339Nano
+	TimeFormatUnix
+	TimeFormatUnixMilli
+	TimeFormatUnixNano
+	// TimeFormatLayout uses ValueFormat.TimeLayout.
+	TimeFormatLayout
+)
+
+type DurationFormat int
+
+const (
+	// DurationFormatDefault keeps time.Duration values, and renders strings with time.Duration.String.
+	DurationFormatDefault DurationFormat = iota
+	DurationFormatString
+	DurationFormatSeconds
+	DurationFormatMillis
+	DurationFormatISO8601
+)
+
+// ValueFormat configures how times and durations are converted. The zero value
+// matches ValueToString and AttrToValue, except that nested times are converted
+// to UTC as well.
+type ValueFormat struct {
+	Time       TimeFormat
+	TimeLayout string
+	// PreserveLocation disables the conversion of times to UTC.
+	PreserveLocation bool
+
+	Duration DurationFormat
+}
+
+func (f ValueFormat) AttrToValue(attr slog.Attr) (string, any) {
+	v := attr.Value.Resolve()
+
+	switch v.Kind() {
+	case slog.KindGroup:
+		return attr.Key, f.AttrsToMap(v.Group()...)
+	case slog.KindTime:
+		return attr.Key, f.timeValue(v.Time())
+	case slog.KindDuration:
+		return attr.Key, f.durationValue(v.Duration())
+	default:
+		return AttrToValue(slog.Attr{Key: attr.Key, Value: v})
+	}
+}
+
+func (f ValueFormat) ValueToString(v slog.Value) string {
+	v = v.Resolve()
+
+	switch v.Kind() {
+	case slog.KindTime:
+		return f.timeString(v.Time())
+	case slog.KindDuration:
+		return f.durationString(v.Duration())
+	case slog.KindAny, slog.KindLogValuer, slog.KindGroup:
+		return AnyValueToString(v)
+	default:
+		return v.String()
+	}
+}
+
+func (f ValueFormat) AttrsToString(attrs ...slog.Attr) map[string]string {
+	output := make(map[string]string, len(attrs))
+
+	for i := range attrs {
+		output[attrs[i].Key] = f.ValueToString(attrs[i].Value)
+	}
+
+	return output
+}
+
+// AttrsToMap follows the AttrsToMap semantics, with formatted times and durations.
+func (f ValueFormat) AttrsToMap(attrs ...slog.Attr) map[string]any {
+	output := map[string]any{}
+
+	for _, attr := range mergeAttrsByKey(attrs) {
+		_, output[attr.Key] = f.AttrToValue(attr)
+	}
+
+	return output
+}
+
+func (f ValueFormat) location(t time.Time) time.Time {
+	if f.PreserveLocation {
+		return t
+	}
+
+	return t.UTC()
+}
+
+func (f ValueFormat) timeValue(t time.Time) any {
+	t = f.location(t)
+
+	switch f.Time {
+	case TimeFormatUnix:
+		return t.Unix()
+	case TimeFormatUnixMilli:
+		return t.UnixMilli()
+	case TimeFormatUnixNano:
+		return t.UnixNano()
+	case TimeFormatRFC3339Nano, TimeFormatLayout:
+		return f.timeString(t)
+	default:
+		return t
+	}
+}
+
+func (f ValueFormat) timeString(t time.Time) string {
+	t = f.location(t)
+
+	switch f.Time {
+	case TimeFormatRFC3339Nano:
+		return t.Format(time.RFC3339Nano)
+	case TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case TimeFormatUnixNano:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	case TimeFormatLayout:
+		return t.Format(f.TimeLayout)
+	default:
+		return t.String()
+	}
+}
+
+func (f ValueFormat) durationValue(d time.Duration) any {
+	switch f.Duration {
+	case DurationFormatString, DurationFormatISO8601:
+		return f.durationString(d)
+	case DurationFormatSeconds:
+		return d.Seconds()
+	case DurationFormatMillis:
+		return d.Milliseconds()
+	default:
+		return d
+	}
+}
+
+func (f ValueFormat) durationString(d time.Duration) string {
+	switch f.Duration {
+	case DurationFormatSeconds:
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	case DurationFormatMillis:
+		return strconv.FormatInt(d.Milliseconds(), 10)
+	case DurationFormatISO8601:
+		return ISO8601Duration(d)
+	default:
+		return d.String()
+	}
+}
+
+// ISO8601Duration formats d as "PT1H2M3.5S". Days are not used, since they are
+// not always 24 hours long.
+func ISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var sb strings.Builder
+	if d < 0 {
+		sb.WriteByte('-')
+		// math.MinInt64 cannot be negated
+		if d == -d {
+			d++
+		}
+		d = -d
+	}
+	sb.WriteString("PT")
+
+	if h := d / time.Hour; h > 0 {
+		sb.WriteString(strconv.FormatInt(int64(h), 10))
+		sb.WriteByte('H')
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		sb.WriteString(strconv.FormatInt(int64(m), 10))
+		sb.WriteByte('M')
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		sb.WriteString(strconv.FormatInt(int64(d/time.Second), 10))
+		if nanos := int64(d % time.Second); nanos > 0 {
+			sb.WriteByte('.')
+			sb.WriteString(strings.TrimRight(strconv.FormatInt(1e9+nanos, 10)[1:], "0"))
+		}
+		sb.WriteByte('S')
+	}
+
+	return sb.String()
+}
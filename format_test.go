@@ -0,0 +1,90 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueFormat(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	paris := time.FixedZone("CET", 3600)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, paris)
+	d := 1500 * time.Millisecond
+
+	// default
+	f := ValueFormat{}
+	is.Equal(ValueToString(slog.TimeValue(ts)), f.ValueToString(slog.TimeValue(ts)))
+	is.Equal("2024-01-02 02:04:05.123456789 +0000 UTC", f.ValueToString(slog.TimeValue(ts)))
+	is.Equal("1.5s", f.ValueToString(slog.DurationValue(d)))
+	_, v := f.AttrToValue(slog.Time("t", ts))
+	is.Equal(ts.UTC(), v)
+	_, v = f.AttrToValue(slog.Duration("d", d))
+	is.Equal(d, v)
+
+	for format, expected := range map[TimeFormat]any{
+		TimeFormatRFC3339Nano: "2024-01-02T02:04:05.123456789Z",
+		TimeFormatUnix:        int64(1704161045),
+		TimeFormatUnixMilli:   int64(1704161045123),
+		TimeFormatUnixNano:    int64(1704161045123456789),
+	} {
+		f := ValueFormat{Time: format}
+		_, v := f.AttrToValue(slog.Time("t", ts))
+		is.Equal(expected, v)
+	}
+
+	f = ValueFormat{Time: TimeFormatLayout, TimeLayout: time.DateTime, PreserveLocation: true}
+	is.Equal("2024-01-02 03:04:05", f.ValueToString(slog.TimeValue(ts)))
+	f = ValueFormat{Time: TimeFormatRFC3339Nano, PreserveLocation: true}
+	is.Equal("2024-01-02T03:04:05.123456789+01:00", f.ValueToString(slog.TimeValue(ts)))
+	f = ValueFormat{Time: TimeFormatUnixMilli}
+	is.Equal("1704161045123", f.ValueToString(slog.TimeValue(ts)))
+
+	for format, expected := range map[DurationFormat][2]any{
+		DurationFormatString:  {"1.5s", "1.5s"},
+		DurationFormatSeconds: {1.5, "1.5"},
+		DurationFormatMillis:  {int64(1500), "1500"},
+		DurationFormatISO8601: {"PT1.5S", "PT1.5S"},
+	} {
+		f := ValueFormat{Duration: format}
+		_, v := f.AttrToValue(slog.Duration("d", d))
+		is.Equal(expected[0], v)
+		is.Equal(expected[1], f.ValueToString(slog.DurationValue(d)))
+	}
+
+	// nested
+	f = ValueFormat{Time: TimeFormatUnix, Duration: DurationFormatMillis}
+	is.Equal(
+		map[string]any{
+			"g":   map[string]any{"t": int64(1704161045), "d": int64(1500)},
+			"str": "a",
+			"int": int64(1),
+		},
+		f.AttrsToMap(
+			slog.Group("g", slog.Time("t", ts)),
+			slog.Group("g", slog.Duration("d", d)),
+			slog.String("str", "a"),
+			slog.Int("int", 1),
+		),
+	)
+	is.Equal(
+		map[string]string{"t": "1704161045", "d": "1500", "b": "true"},
+		f.AttrsToString(slog.Time("t", ts), slog.Duration("d", d), slog.Bool("b", true)),
+	)
+}
+
+func TestISO8601Duration(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("PT0S", ISO8601Duration(0))
+	is.Equal("PT1H", ISO8601Duration(time.Hour))
+	is.Equal("PT26H3M4.05S", ISO8601Duration(26*time.Hour+3*time.Minute+4050*time.Millisecond))
+	is.Equal("PT0.000000001S", ISO8601Duration(time.Nanosecond))
+	is.Equal("-PT1M30S", ISO8601Duration(-90*time.Second))
+	is.NotEmpty(ISO8601Duration(time.Duration(-1 << 63)))
+}
@@ -1,7 +1,9 @@
 package slogcommon
 
 import (
+	"errors"
 	"log/slog"
+	"math"
 	"testing"
 	"time"
 )
@@ -112,3 +114,124 @@ func FuzzAttrsToString(f *testing.F) {
 		_ = AttrsToString(attrs...)
 	})
 }
+
+func FuzzBinaryRoundTrip(f *testing.F) {
+	f.Add("key", "value", int64(-42), uint64(42), 3.14, true, int64(time.Second), int64(1700000000), uint8(1))
+	f.Add("", "", int64(0), uint64(0), 0.0, false, int64(0), int64(0), uint8(0))
+	f.Add("unicode: 日本語", "\x00\xff", int64(math.MinInt64), uint64(math.MaxUint64), math.NaN(), true, int64(math.MaxInt64), int64(-62135596800), uint8(binaryMaxDepth))
+	f.Add("deep", "", int64(0), uint64(0), 0.0, false, int64(0), int64(0), uint8(70))
+
+	f.Fuzz(func(t *testing.T, k, s string, i int64, u uint64, fl float64, b bool, d int64, sec int64, depth uint8) {
+		tm := time.Unix(sec, 0).UTC()
+		nested := slog.Int64(k, i)
+		for n := 0; n < int(depth); n++ {
+			nested = slog.Group(k, nested)
+		}
+		attrs := []slog.Attr{
+			slog.String(k, s),
+			slog.Int64(k+"_int", i),
+			slog.Uint64(k+"_uint", u),
+			slog.Float64(k+"_float", fl),
+			slog.Bool(k+"_bool", b),
+			slog.Duration(k+"_dur", time.Duration(d)),
+			slog.Time(k+"_time", tm),
+			slog.Group(k+"_group", slog.String(s, k), slog.Any("nil", nil)),
+			nested,
+		}
+		record := slog.NewRecord(tm, slog.Level(i%100), s, 0)
+
+		// groups too deep for the decoder are rejected by the encoder
+		data, err := AppendBinaryRecord(nil, &record, attrs)
+		if depth > binaryMaxDepth {
+			if !errors.Is(err, ErrBinaryTooDeep) {
+				t.Fatalf("depth %d: expected ErrBinaryTooDeep, got %v", depth, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, decodedAttrs, err := DecodeBinaryRecord(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Time.Equal(record.Time) || decoded.Level != record.Level || decoded.Message != record.Message {
+			t.Fatalf("record mismatch: %v != %v", decoded, record)
+		}
+		if len(decodedAttrs) != len(attrs) {
+			t.Fatalf("got %d attrs, expected %d", len(decodedAttrs), len(attrs))
+		}
+		for idx := range attrs {
+			expected, actual := attrs[idx], decodedAttrs[idx]
+			if expected.Key != actual.Key || expected.Value.Kind() != actual.Value.Kind() {
+				t.Fatalf("attr %d mismatch: %v != %v", idx, actual, expected)
+			}
+			if expected.Value.Kind() == slog.KindFloat64 {
+				if math.Float64bits(expected.Value.Float64()) != math.Float64bits(actual.Value.Float64()) {
+					t.Fatalf("float mismatch: %v != %v", actual, expected)
+				}
+				continue
+			}
+			if !expected.Value.Equal(actual.Value) {
+				t.Fatalf("attr %d mismatch: %v != %v", idx, actual, expected)
+			}
+		}
+	})
+}
+
+func FuzzDecodeBinary(f *testing.F) {
+	record := slog.NewRecord(time.Unix(1, 0), slog.LevelInfo, "msg", 0)
+	data, _ := AppendBinaryRecord(nil, &record, []slog.Attr{slog.Group("g", slog.Int("a", 1)), slog.Any("any", []int{1, 2})})
+	f.Add(data)
+	f.Add([]byte("SLG\x01"))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Should not panic
+		_, _, _ = DecodeBinaryRecord(data)
+		_, _ = DecodeBinaryAttrs(data)
+	})
+}
+
+type fuzzLogValuer struct {
+	key   string
+	depth int
+	self  *fuzzLogValuer
+}
+
+func (v *fuzzLogValuer) LogValue() slog.Value {
+	if v.depth <= 0 {
+		return slog.GroupValue(slog.String(v.key, "leaf"), slog.Any("self", v.self))
+	}
+	return slog.GroupValue(slog.Any(v.key, &fuzzLogValuer{key: v.key, depth: v.depth - 1, self: v.self}))
+}
+
+func FuzzSafeResolveAttrs(f *testing.F) {
+	f.Add("key", 3, true, 2)
+	f.Add("", 0, false, 0)
+	f.Add("deep", 1000, true, 10)
+
+	f.Fuzz(func(t *testing.T, key string, depth int, cyclic bool, maxDepth int) {
+		depth %= 2000
+		if depth < 0 {
+			depth = -depth
+		}
+
+		v := &fuzzLogValuer{key: key, depth: depth}
+		if cyclic {
+			v.self = v
+		}
+
+		// nested plain groups
+		nested := slog.Any(key, v)
+		for i := 0; i < depth%100; i++ {
+			nested = slog.Group(key, nested)
+		}
+
+		// Should not panic nor overflow the stack
+		attrs, _ := SafeResolveAttrs(SafeResolveOptions{MaxDepth: maxDepth % 64}, nested, slog.Any("v", v))
+		_ = AttrsToMap(attrs...)
+		_ = SafeAttrsToMap(SafeResolveOptions{}, nested)
+	})
+}
@@ -0,0 +1,240 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type KeyCase int
+
+const (
+	KeyCaseUnchanged KeyCase = iota
+	KeyCaseSnake
+	KeyCaseCamel
+)
+
+const DefaultReservedKeyPrefix = "fields."
+
+var (
+	DefaultGELFReservedKeys          = []string{"_id"}
+	DefaultElasticsearchReservedKeys = []string{"@timestamp", "_id", "_index", "_source", "_type", "_routing"}
+)
+
+type KeyNormalizerOptions struct {
+	Case KeyCase
+	// Sanitize is applied after case conversion (optional). See PrometheusLabelName
+	// and ElasticsearchFieldName.
+	Sanitize func(key string) string
+	// Prefix is prepended to top-level keys.
+	Prefix string
+	// Reserved top-level keys are renamed with ReservedPrefix, which defaults to "fields.".
+	Reserved       []string
+	ReservedPrefix string
+}
+
+// KeyNormalizer rewrites attribute keys, including group keys.
+type KeyNormalizer struct {
+	opts KeyNormalizerOptions
+}
+
+func NewKeyNormalizer(opts KeyNormalizerOptions) *KeyNormalizer {
+	if opts.ReservedPrefix == "" {
+		opts.ReservedPrefix = DefaultReservedKeyPrefix
+	}
+
+	return &KeyNormalizer{opts: opts}
+}
+
+// Key normalizes a key found under groups.
+func (n *KeyNormalizer) Key(groups []string, key string) string {
+	switch n.opts.Case {
+	case KeyCaseSnake:
+		key = ToSnakeCase(key)
+	case KeyCaseCamel:
+		key = ToCamelCase(key)
+	}
+
+	if n.opts.Sanitize != nil {
+		key = n.opts.Sanitize(key)
+	}
+
+	if len(groups) > 0 {
+		return key
+	}
+
+	key = n.opts.Prefix + key
+	if slices.Contains(n.opts.Reserved, key) {
+		key = n.opts.ReservedPrefix + key
+	}
+
+	return key
+}
+
+// NormalizeAttrs rewrites keys recursively. LogValuers are resolved with
+// SafeResolveAttrs.
+func (n *KeyNormalizer) NormalizeAttrs(groups []string, attrs ...slog.Attr) []slog.Attr {
+	resolved, _ := SafeResolveAttrs(SafeResolveOptions{}, attrs...)
+	return n.normalizeAttrs(groups, resolved)
+}
+
+// ReplaceAttr returns a ReplaceAttrFn renaming keys. Since slog and ReplaceAttrs
+// never call it on group attributes, group keys are left untouched, except for
+// groups produced by LogValuers. Use NormalizeAttrs to rename them as well.
+func (n *KeyNormalizer) ReplaceAttr() ReplaceAttrFn {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		// only LogValuers may produce nested groups
+		if a.Value.Kind() == slog.KindLogValuer {
+			resolved, _ := SafeResolveAttrs(SafeResolveOptions{}, a)
+			a = resolved[0]
+		}
+		return n.normalizeAttr(groups, a)
+	}
+}
+
+// normalizeAttrs expects resolved attributes.
+func (n *KeyNormalizer) normalizeAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	output := make([]slog.Attr, 0, len(attrs))
+
+	for _, attr := range attrs {
+		output = append(output, n.normalizeAttr(groups, attr))
+	}
+
+	return output
+}
+
+func (n *KeyNormalizer) normalizeAttr(groups []string, attr slog.Attr) slog.Attr {
+	v := attr.Value
+
+	// inlined groups keep an empty key
+	if v.Kind() == slog.KindGroup && attr.Key == "" {
+		return slog.Attr{Value: slog.GroupValue(n.normalizeAttrs(groups, v.Group())...)}
+	}
+
+	key := n.Key(groups, attr.Key)
+	if v.Kind() == slog.KindGroup {
+		v = slog.GroupValue(n.normalizeAttrs(append(groups, attr.Key), v.Group())...)
+	}
+
+	return slog.Attr{Key: key, Value: v}
+}
+
+// ToSnakeCase converts "HTTPStatus", "userId" or "user-id" into "http_status",
+// "user_id" and "user_id". Leading underscores are kept.
+func ToSnakeCase(s string) string {
+	rest := strings.TrimLeft(s, "_")
+	return s[:len(s)-len(rest)] + strings.Join(splitKeyWords(rest), "_")
+}
+
+// ToCamelCase converts "http_status" or "HTTPStatus" into "httpStatus". Leading
+// underscores are kept.
+func ToCamelCase(s string) string {
+	rest := strings.TrimLeft(s, "_")
+	words := splitKeyWords(rest)
+	for i := 1; i < len(words); i++ {
+		runes := []rune(words[i])
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	return s[:len(s)-len(rest)] + strings.Join(words, "")
+}
+
+// splitKeyWords splits on '_', '-' and spaces, and on case changes, returning
+// lowercase words. Acronyms are kept together: "HTTPServer" gives "http" and "server".
+func splitKeyWords(s string) []string {
+	runes := []rune(s)
+	words := []string{}
+
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+		}
+	}
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			flush(i)
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+
+		prev := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+
+	return words
+}
+
+// PrometheusLabelName sanitizes a key to match [a-zA-Z_][a-zA-Z0-9_]*. Keys
+// starting with "__" are reserved and get prefixed.
+func PrometheusLabelName(key string) string {
+	var sb strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9' && i > 0) {
+			sb.WriteByte(c)
+		} else if c >= '0' && c <= '9' {
+			sb.WriteByte('_')
+			sb.WriteByte(c)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+
+	output := sb.String()
+	if output == "" {
+		return "_"
+	}
+	if strings.HasPrefix(output, "__") {
+		return "label" + output
+	}
+
+	return output
+}
+
+// ElasticsearchFieldName sanitizes a key for Elasticsearch: dots would be
+// interpreted as object paths and are replaced, like other control or
+// whitespace characters. Empty keys become "_".
+func ElasticsearchFieldName(key string) string {
+	key = strings.Map(func(r rune) rune {
+		if r == '.' || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, key)
+
+	if key == "" {
+		return "_"
+	}
+
+	return key
+}
+
+// truncateRunes cuts s to at most maxLen runes.
+func truncateRunes(s string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+
+	runes := 0
+	for i := range s {
+		if runes == maxLen {
+			return s[:i]
+		}
+		runes++
+	}
+
+	return s
+}
@@ -0,0 +1,109 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyCase(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	for input, expected := range map[string]string{
+		"":           "",
+		"id":         "id",
+		"userId":     "user_id",
+		"UserID":     "user_id",
+		"HTTPStatus": "http_status",
+		"user-name":  "user_name",
+		"user name":  "user_name",
+		"__a__b":     "__a_b",
+		"ipv4Addr":   "ipv4_addr",
+		"http.host":  "http.host",
+		"éÉté":       "é_été",
+	} {
+		is.Equal(expected, ToSnakeCase(input), input)
+	}
+
+	for input, expected := range map[string]string{
+		"":            "",
+		"id":          "id",
+		"user_id":     "userId",
+		"HTTPStatus":  "httpStatus",
+		"Status-Code": "statusCode",
+		"userID":      "userId",
+		"_source_id":  "_sourceId",
+	} {
+		is.Equal(expected, ToCamelCase(input), input)
+	}
+}
+
+func TestKeySanitizers(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("http_status", PrometheusLabelName("http.status"))
+	is.Equal("_1a", PrometheusLabelName("1a"))
+	is.Equal("http_status", ElasticsearchFieldName("http.status"))
+	is.Equal("a_b", ElasticsearchFieldName("a\nb"))
+	is.Equal("_", ElasticsearchFieldName(""))
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	n := NewKeyNormalizer(KeyNormalizerOptions{
+		Case:     KeyCaseSnake,
+		Sanitize: ElasticsearchFieldName,
+		Reserved: DefaultElasticsearchReservedKeys,
+	})
+
+	attrs := n.NormalizeAttrs(
+		nil,
+		slog.String("@timestamp", "now"),
+		slog.String("_id", "1"),
+		slog.Group("httpRequest", slog.Int("statusCode", 200), slog.String("_id", "nested")),
+		slog.Group("", slog.String("inlinedKey", "v")),
+		slog.Any("user", stubLogValuer),
+		slog.String("a.b", "c"),
+	)
+	is.Equal([]slog.Attr{
+		slog.String("fields.@timestamp", "now"),
+		slog.String("fields._id", "1"),
+		slog.Group("http_request", slog.Int("status_code", 200), slog.String("_id", "nested")),
+		slog.Group("", slog.String("inlined_key", "v")),
+		slog.Group("user", slog.String("name", "userName"), slog.String("password", "********")),
+		slog.String("a_b", "c"),
+	}, attrs)
+	is.Equal("fields.@timestamp", attrs[0].Key)
+
+	// prefix
+	n = NewKeyNormalizer(KeyNormalizerOptions{
+		Case:           KeyCaseCamel,
+		Prefix:         "app_",
+		Reserved:       []string{"app_id"},
+		ReservedPrefix: "x_",
+	})
+	is.Equal("app_userId", n.Key(nil, "user_id"))
+	is.Equal("x_app_id", n.Key(nil, "id"))
+	is.Equal("userId", n.Key([]string{"g"}, "user_id"))
+
+	// ReplaceAttrFn
+	attrs = ReplaceAttrs(n.ReplaceAttr(), nil, slog.String("user_id", "1"), slog.Group("my_group", slog.String("the_key", "v")))
+	is.Equal([]slog.Attr{
+		slog.String("app_userId", "1"),
+		slog.Group("my_group", slog.String("theKey", "v")),
+	}, attrs)
+	is.Equal("app_userId", attrs[0].Key)
+	is.Equal("theKey", attrs[1].Value.Group()[0].Key)
+
+	// recursive LogValuers are cut
+	attrs = n.NormalizeAttrs(nil, slog.Any("the_value", testRecursiveLogValuer{}))
+	is.Len(attrs, 1)
+	is.Equal("app_theValue", attrs[0].Key)
+	attr := n.ReplaceAttr()(nil, slog.Any("the_value", testRecursiveLogValuer{}))
+	is.Equal("app_theValue", attr.Key)
+}
@@ -0,0 +1,326 @@
+package slogcommon
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const levelControllerMaxBodySize = 64 << 10
+
+type LevelControllerOptions struct {
+	// Audit logs level changes at Info level (optional).
+	Audit *slog.Logger
+	// OnChange is called after each level change (optional).
+	OnChange func(change LevelChange)
+}
+
+// LevelChange describes a level update. Name is empty for the root level.
+type LevelChange struct {
+	Time     time.Time
+	Name     string
+	Previous slog.Level
+	Level    slog.Level
+	// Removed is true when a named override is removed.
+	Removed bool
+	// TTL is set for temporary changes.
+	TTL time.Duration
+	// Source is "http <remote addr>" for changes made through ServeHTTP, "ttl" for
+	// reverts, and empty otherwise.
+	Source string
+}
+
+func (c LevelChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("name", c.Name),
+		slog.String("previous", DefaultLevelRegistry.Name(c.Previous)),
+		slog.String("level", DefaultLevelRegistry.Name(c.Level)),
+	}
+	if c.Removed {
+		attrs = append(attrs, slog.Bool("removed", true))
+	}
+	if c.TTL > 0 {
+		attrs = append(attrs, slog.Duration("ttl", c.TTL))
+	}
+	if c.Source != "" {
+		attrs = append(attrs, slog.String("source", c.Source))
+	}
+
+	return attrs
+}
+
+type levelControllerState struct {
+	level slog.Level
+	set   bool
+}
+
+// LevelController holds a root level and per-logger-name overrides, adjustable at
+// runtime. Overrides may be temporary and revert to the previous level after a TTL.
+// Close must be called to stop pending reverts. Changes made after Close are
+// permanent.
+type LevelController struct {
+	root *slog.LevelVar
+	opts LevelControllerOptions
+
+	mu        sync.RWMutex
+	overrides map[string]slog.Level
+	reverts   map[string]*levelControllerRevert
+	closed    bool
+}
+
+type levelControllerRevert struct {
+	timer *time.Timer
+	// previous is the state restored once expired.
+	previous levelControllerState
+}
+
+func NewLevelController(level slog.Level, opts LevelControllerOptions) *LevelController {
+	root := &slog.LevelVar{}
+	root.Set(level)
+
+	return &LevelController{
+		root:      root,
+		opts:      opts,
+		overrides: map[string]slog.Level{},
+		reverts:   map[string]*levelControllerRevert{},
+	}
+}
+
+// Level implements slog.Leveler with the root level.
+func (c *LevelController) Level() slog.Level {
+	return c.root.Level()
+}
+
+// Leveler returns the level of a named logger, following updates.
+func (c *LevelController) Leveler(name string) slog.Leveler {
+	return levelControllerLeveler{controller: c, name: name}
+}
+
+// LevelOf returns the override of name, or the root level.
+func (c *LevelController) LevelOf(name string) slog.Level {
+	c.mu.RLock()
+	level, ok := c.overrides[name]
+	c.mu.RUnlock()
+
+	if ok {
+		return level
+	}
+
+	return c.root.Level()
+}
+
+func (c *LevelController) Overrides() map[string]slog.Level {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	output := make(map[string]slog.Level, len(c.overrides))
+	for name, level := range c.overrides {
+		output[name] = level
+	}
+
+	return output
+}
+
+// SetLevel sets the root level. A positive ttl reverts the change once expired.
+func (c *LevelController) SetLevel(level slog.Level, ttl time.Duration) {
+	c.set("", levelControllerState{level: level, set: true}, ttl, "", nil)
+}
+
+// SetOverride sets the level of a named logger. A positive ttl reverts the change
+// once expired.
+func (c *LevelController) SetOverride(name string, level slog.Level, ttl time.Duration) {
+	c.set(name, levelControllerState{level: level, set: true}, ttl, "", nil)
+}
+
+func (c *LevelController) RemoveOverride(name string) {
+	c.set(name, levelControllerState{}, 0, "", nil)
+}
+
+// Close cancels pending reverts. Later TTLs are ignored.
+func (c *LevelController) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	for name, revert := range c.reverts {
+		revert.timer.Stop()
+		delete(c.reverts, name)
+	}
+}
+
+// set applies a change. expired is set by reverts, which are skipped when the
+// change they revert was replaced in the meantime. A temporary change replacing
+// another one keeps its revert target, so that the level set before the first
+// temporary change is eventually restored.
+func (c *LevelController) set(name string, state levelControllerState, ttl time.Duration, source string, expired *levelControllerRevert) {
+	c.mu.Lock()
+
+	if expired != nil && c.reverts[name] != expired {
+		c.mu.Unlock()
+		return
+	}
+
+	if c.closed {
+		ttl = 0
+	}
+
+	current := c.stateLocked(name)
+	previous := current
+	if revert, ok := c.reverts[name]; ok {
+		revert.timer.Stop()
+		delete(c.reverts, name)
+		previous = revert.previous
+	}
+
+	if name == "" {
+		c.root.Set(state.level)
+	} else if state.set {
+		c.overrides[name] = state.level
+	} else {
+		delete(c.overrides, name)
+	}
+
+	if ttl > 0 {
+		revert := &levelControllerRevert{previous: previous}
+		revert.timer = time.AfterFunc(ttl, func() {
+			c.set(name, revert.previous, 0, "ttl", revert)
+		})
+		c.reverts[name] = revert
+	}
+
+	change := LevelChange{
+		Time:     time.Now(),
+		Name:     name,
+		Previous: c.levelOfState(current),
+		Level:    c.levelOfState(state),
+		Removed:  name != "" && !state.set,
+		TTL:      ttl,
+		Source:   source,
+	}
+
+	c.mu.Unlock()
+
+	if c.opts.Audit != nil {
+		c.opts.Audit.LogAttrs(context.Background(), slog.LevelInfo, "log level changed", change.Attrs()...)
+	}
+	if c.opts.OnChange != nil {
+		c.opts.OnChange(change)
+	}
+}
+
+func (c *LevelController) stateLocked(name string) levelControllerState {
+	if name == "" {
+		return levelControllerState{level: c.root.Level(), set: true}
+	}
+
+	level, ok := c.overrides[name]
+	return levelControllerState{level: level, set: ok}
+}
+
+func (c *LevelController) levelOfState(state levelControllerState) slog.Level {
+	if state.set {
+		return state.level
+	}
+
+	return c.root.Level()
+}
+
+type levelControllerLeveler struct {
+	controller *LevelController
+	name       string
+}
+
+func (l levelControllerLeveler) Level() slog.Level {
+	return l.controller.LevelOf(l.name)
+}
+
+type levelControllerResponse struct {
+	Level     string            `json:"level"`
+	Overrides map[string]string `json:"overrides"`
+}
+
+type levelControllerRequest struct {
+	// Name is empty for the root level.
+	Name string `json:"name"`
+	// Level is parsed with ParseLevel. An empty level removes the override of Name.
+	Level string `json:"level"`
+	// TTL is parsed with time.ParseDuration (optional).
+	TTL string `json:"ttl"`
+}
+
+// ServeHTTP exposes levels in JSON:
+//
+//	GET  -> {"level":"INFO","overrides":{"db":"DEBUG"}}
+//	PUT  <- {"name":"db","level":"debug","ttl":"5m"}
+func (c *LevelController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levelControllerRequest
+		body := http.MaxBytesReader(w, r.Body, levelControllerMaxBodySize)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			status := http.StatusBadRequest
+			if _, ok := err.(*http.MaxBytesError); ok {
+				status = http.StatusRequestEntityTooLarge
+			}
+			levelControllerError(w, status, "invalid body: "+err.Error())
+			return
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			d, err := time.ParseDuration(req.TTL)
+			if err != nil || d < 0 {
+				levelControllerError(w, http.StatusBadRequest, "invalid ttl: "+req.TTL)
+				return
+			}
+			ttl = d
+		}
+
+		source := "http " + r.RemoteAddr
+		if req.Level == "" {
+			if req.Name == "" {
+				levelControllerError(w, http.StatusBadRequest, "missing level")
+				return
+			}
+			c.set(req.Name, levelControllerState{}, 0, source, nil)
+			break
+		}
+
+		level, err := ParseLevel(req.Level)
+		if err != nil {
+			levelControllerError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		c.set(req.Name, levelControllerState{level: level, set: true}, ttl, source, nil)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		levelControllerError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	overrides := c.Overrides()
+	response := levelControllerResponse{
+		Level:     DefaultLevelRegistry.Name(c.Level()),
+		Overrides: make(map[string]string, len(overrides)),
+	}
+	for name, level := range overrides {
+		response.Overrides[name] = DefaultLevelRegistry.Name(level)
+	}
+
+	levelControllerJSON(w, http.StatusOK, response)
+}
+
+func levelControllerError(w http.ResponseWriter, status int, message string) {
+	levelControllerJSON(w, status, map[string]string{"error": message})
+}
+
+func levelControllerJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
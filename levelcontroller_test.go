@@ -0,0 +1,195 @@
+package slogcommon
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelController(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var mu sync.Mutex
+	var changes []LevelChange
+	c := NewLevelController(slog.LevelInfo, LevelControllerOptions{
+		OnChange: func(change LevelChange) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, change)
+		},
+	})
+	defer c.Close()
+
+	db := c.Leveler("db")
+	is.Equal(slog.LevelInfo, c.Level())
+	is.Equal(slog.LevelInfo, db.Level())
+
+	c.SetOverride("db", slog.LevelDebug, 0)
+	is.Equal(slog.LevelDebug, db.Level())
+	is.Equal(slog.LevelInfo, c.Level())
+	is.Equal(map[string]slog.Level{"db": slog.LevelDebug}, c.Overrides())
+
+	c.SetLevel(slog.LevelWarn, 0)
+	is.Equal(slog.LevelDebug, db.Level())
+	is.Equal(slog.LevelWarn, c.Leveler("http").Level())
+
+	c.RemoveOverride("db")
+	is.Equal(slog.LevelWarn, db.Level())
+	is.Empty(c.Overrides())
+
+	mu.Lock()
+	is.Len(changes, 3)
+	is.Equal("db", changes[0].Name)
+	is.Equal(slog.LevelInfo, changes[0].Previous)
+	is.Equal(slog.LevelDebug, changes[0].Level)
+	is.Equal("", changes[1].Name)
+	is.True(changes[2].Removed)
+	is.Equal(slog.LevelDebug, changes[2].Previous)
+	is.Equal(slog.LevelWarn, changes[2].Level)
+	mu.Unlock()
+
+	// usable by handlers
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: db}))
+	logger.Info("dropped")
+	c.SetOverride("db", slog.LevelInfo, 0)
+	logger.Info("kept")
+	is.NotContains(buf.String(), "dropped")
+	is.Contains(buf.String(), "kept")
+}
+
+func TestLevelControllerTTL(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var audit bytes.Buffer
+	var mu sync.Mutex
+	c := NewLevelController(slog.LevelInfo, LevelControllerOptions{
+		Audit: slog.New(slog.NewTextHandler(&syncWriter{mu: &mu, w: &audit}, nil)),
+	})
+	defer c.Close()
+
+	c.SetOverride("db", slog.LevelWarn, 0)
+	c.SetOverride("db", slog.LevelDebug, 20*time.Millisecond)
+	c.SetLevel(slog.LevelError, 20*time.Millisecond)
+	is.Equal(slog.LevelDebug, c.LevelOf("db"))
+	is.Equal(slog.LevelError, c.Level())
+
+	is.Eventually(func() bool {
+		return c.LevelOf("db") == slog.LevelWarn && c.Level() == slog.LevelInfo
+	}, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	is.Contains(audit.String(), `msg="log level changed" name=db previous=WARN level=DEBUG ttl=20ms`)
+	is.Contains(audit.String(), `msg="log level changed" name=db previous=DEBUG level=WARN source=ttl`)
+	mu.Unlock()
+
+	// a change cancels the pending revert
+	c.SetOverride("db", slog.LevelDebug, 20*time.Millisecond)
+	c.SetOverride("db", slog.LevelError, 0)
+	time.Sleep(50 * time.Millisecond)
+	is.Equal(slog.LevelError, c.LevelOf("db"))
+
+	// a temporary change replacing another one restores the original level
+	c.SetOverride("db", slog.LevelDebug, time.Hour)
+	c.SetOverride("db", LevelTrace, 10*time.Millisecond)
+	is.Equal(LevelTrace, c.LevelOf("db"))
+	is.Eventually(func() bool {
+		return c.LevelOf("db") == slog.LevelError
+	}, time.Second, 5*time.Millisecond)
+
+	// temporary override of a logger without override
+	c.SetOverride("http", slog.LevelDebug, 10*time.Millisecond)
+	is.Eventually(func() bool {
+		_, ok := c.Overrides()["http"]
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+
+	// Close cancels reverts
+	c.SetLevel(slog.LevelDebug, 10*time.Millisecond)
+	c.Close()
+	time.Sleep(30 * time.Millisecond)
+	is.Equal(slog.LevelDebug, c.Level())
+
+	// TTLs are ignored once closed
+	c.SetOverride("db", slog.LevelWarn, 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	is.Equal(slog.LevelWarn, c.LevelOf("db"))
+}
+
+func TestLevelControllerHTTP(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var changes []LevelChange
+	c := NewLevelController(slog.LevelInfo, LevelControllerOptions{
+		OnChange: func(change LevelChange) { changes = append(changes, change) },
+	})
+	defer c.Close()
+
+	do := func(method string, body string) (int, map[string]any) {
+		req := httptest.NewRequest(method, "/log/level", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		c.ServeHTTP(rec, req)
+
+		var output map[string]any
+		is.NoError(json.Unmarshal(rec.Body.Bytes(), &output))
+		is.Equal("application/json", rec.Header().Get("Content-Type"))
+		return rec.Code, output
+	}
+
+	code, output := do(http.MethodGet, "")
+	is.Equal(http.StatusOK, code)
+	is.Equal(map[string]any{"level": "INFO", "overrides": map[string]any{}}, output)
+
+	code, output = do(http.MethodPut, `{"name":"db","level":"trace"}`)
+	is.Equal(http.StatusOK, code)
+	is.Equal(map[string]any{"level": "INFO", "overrides": map[string]any{"db": "TRACE"}}, output)
+
+	code, output = do(http.MethodPut, `{"level":"warn+1","ttl":"1h"}`)
+	is.Equal(http.StatusOK, code)
+	is.Equal("WARN+1", output["level"])
+
+	code, output = do(http.MethodPut, `{"name":"db"}`)
+	is.Equal(http.StatusOK, code)
+	is.Equal(map[string]any{}, output["overrides"])
+
+	is.Len(changes, 3)
+	is.Equal("http 192.0.2.1:1234", changes[0].Source)
+	is.Equal(time.Hour, changes[1].TTL)
+
+	// errors
+	for _, body := range []string{`{`, `{"level":"verbose"}`, `{"level":"info","ttl":"soon"}`, `{"level":"info","ttl":"-1s"}`, `{}`} {
+		code, output = do(http.MethodPut, body)
+		is.Equal(http.StatusBadRequest, code, body)
+		is.NotEmpty(output["error"], body)
+	}
+
+	code, output = do(http.MethodPut, `{"name":"`+strings.Repeat("a", levelControllerMaxBodySize)+`","level":"info"}`)
+	is.Equal(http.StatusRequestEntityTooLarge, code)
+	is.NotEmpty(output["error"])
+
+	code, _ = do(http.MethodPost, `{}`)
+	is.Equal(http.StatusMethodNotAllowed, code)
+	is.Len(changes, 3)
+}
+
+type syncWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,134 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"sort"
+)
+
+type LevelMapping[T comparable] struct {
+	Level slog.Level
+	Value T
+}
+
+// LevelMapper maps slog levels to backend severities. Levels between two entries
+// map to the next-higher entry, so that Warn+2 is reported as an error rather than
+// a warning. Levels above the highest entry map to the highest entry, and levels
+// below the lowest entry map to the lowest entry.
+type LevelMapper[T comparable] struct {
+	entries []LevelMapping[T]
+}
+
+func NewLevelMapper[T comparable](entries ...LevelMapping[T]) *LevelMapper[T] {
+	sorted := append([]LevelMapping[T]{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Level < sorted[j].Level
+	})
+
+	return &LevelMapper[T]{entries: sorted}
+}
+
+func (m *LevelMapper[T]) Map(level slog.Level) T {
+	if len(m.entries) == 0 {
+		var zero T
+		return zero
+	}
+
+	i := sort.Search(len(m.entries), func(i int) bool {
+		return m.entries[i].Level >= level
+	})
+	if i == len(m.entries) {
+		i--
+	}
+
+	return m.entries[i].Value
+}
+
+// Level returns the lowest level mapped to value, for decoders.
+func (m *LevelMapper[T]) Level(value T) (slog.Level, bool) {
+	for _, entry := range m.entries {
+		if entry.Value == value {
+			return entry.Level, true
+		}
+	}
+
+	return 0, false
+}
+
+var (
+	// SyslogLevels maps to RFC 5424 severities (0=Emergency, 7=Debug).
+	SyslogLevels = NewLevelMapper(
+		LevelMapping[int]{slog.LevelDebug, 7},
+		LevelMapping[int]{slog.LevelInfo, 6},
+		LevelMapping[int]{slog.LevelInfo + 2, 5},
+		LevelMapping[int]{slog.LevelWarn, 4},
+		LevelMapping[int]{slog.LevelError, 3},
+		LevelMapping[int]{slog.LevelError + 4, 2},
+		LevelMapping[int]{slog.LevelError + 8, 1},
+		LevelMapping[int]{slog.LevelError + 12, 0},
+	)
+
+	// GELFLevels uses the syslog severities, as GELF does.
+	GELFLevels = SyslogLevels
+
+	SentryLevels = NewLevelMapper(
+		LevelMapping[string]{slog.LevelDebug, "debug"},
+		LevelMapping[string]{slog.LevelInfo, "info"},
+		LevelMapping[string]{slog.LevelWarn, "warning"},
+		LevelMapping[string]{slog.LevelError, "error"},
+		LevelMapping[string]{slog.LevelError + 4, "fatal"},
+	)
+
+	DatadogLevels = NewLevelMapper(
+		LevelMapping[string]{slog.LevelDebug, "debug"},
+		LevelMapping[string]{slog.LevelInfo, "info"},
+		LevelMapping[string]{slog.LevelInfo + 2, "notice"},
+		LevelMapping[string]{slog.LevelWarn, "warning"},
+		LevelMapping[string]{slog.LevelError, "error"},
+		LevelMapping[string]{slog.LevelError + 4, "critical"},
+		LevelMapping[string]{slog.LevelError + 8, "alert"},
+		LevelMapping[string]{slog.LevelError + 12, "emergency"},
+	)
+
+	// OTelLevels maps to OpenTelemetry severity numbers. Every number from
+	// TRACE (1) to FATAL4 (24) has its own level: DEBUG=5, INFO=9, WARN=13, ERROR=17.
+	OTelLevels = newOTelLevelMapper()
+
+	ZapLevels = NewLevelMapper(
+		LevelMapping[string]{slog.LevelDebug, "debug"},
+		LevelMapping[string]{slog.LevelInfo, "info"},
+		LevelMapping[string]{slog.LevelWarn, "warn"},
+		LevelMapping[string]{slog.LevelError, "error"},
+		LevelMapping[string]{slog.LevelError + 4, "dpanic"},
+		LevelMapping[string]{slog.LevelError + 8, "panic"},
+		LevelMapping[string]{slog.LevelError + 12, "fatal"},
+	)
+
+	ZerologLevels = NewLevelMapper(
+		LevelMapping[string]{slog.LevelDebug - 4, "trace"},
+		LevelMapping[string]{slog.LevelDebug, "debug"},
+		LevelMapping[string]{slog.LevelInfo, "info"},
+		LevelMapping[string]{slog.LevelWarn, "warn"},
+		LevelMapping[string]{slog.LevelError, "error"},
+		LevelMapping[string]{slog.LevelError + 4, "fatal"},
+		LevelMapping[string]{slog.LevelError + 8, "panic"},
+	)
+
+	LogrusLevels = NewLevelMapper(
+		LevelMapping[string]{slog.LevelDebug - 4, "trace"},
+		LevelMapping[string]{slog.LevelDebug, "debug"},
+		LevelMapping[string]{slog.LevelInfo, "info"},
+		LevelMapping[string]{slog.LevelWarn, "warning"},
+		LevelMapping[string]{slog.LevelError, "error"},
+		LevelMapping[string]{slog.LevelError + 4, "fatal"},
+		LevelMapping[string]{slog.LevelError + 8, "panic"},
+	)
+)
+
+func newOTelLevelMapper() *LevelMapper[int] {
+	entries := make([]LevelMapping[int], 0, 24)
+	for n := 1; n <= 24; n++ {
+		entries = append(entries, LevelMapping[int]{slog.Level(n - 9), n})
+	}
+
+	return NewLevelMapper(entries...)
+}
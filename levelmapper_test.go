@@ -0,0 +1,76 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelMapper(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	m := NewLevelMapper(
+		LevelMapping[string]{slog.LevelWarn, "warn"},
+		LevelMapping[string]{slog.LevelInfo, "info"},
+		LevelMapping[string]{slog.LevelError, "error"},
+	)
+
+	is.Equal("info", m.Map(slog.LevelDebug))
+	is.Equal("info", m.Map(slog.LevelInfo))
+	is.Equal("warn", m.Map(slog.LevelInfo+1))
+	is.Equal("warn", m.Map(slog.LevelWarn))
+	is.Equal("error", m.Map(slog.LevelWarn+2))
+	is.Equal("error", m.Map(slog.LevelError+100))
+
+	level, ok := m.Level("warn")
+	is.True(ok)
+	is.Equal(slog.LevelWarn, level)
+	_, ok = m.Level("fatal")
+	is.False(ok)
+
+	is.Equal("", NewLevelMapper[string]().Map(slog.LevelInfo))
+}
+
+func TestBuiltinLevelMappers(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal(7, SyslogLevels.Map(slog.LevelDebug-4))
+	is.Equal(5, SyslogLevels.Map(slog.LevelInfo+1))
+	is.Equal(3, SyslogLevels.Map(slog.LevelWarn+2))
+	is.Equal(0, SyslogLevels.Map(slog.LevelError+20))
+	is.Equal(4, GELFLevels.Map(slog.LevelWarn))
+
+	is.Equal("error", SentryLevels.Map(slog.LevelWarn+2))
+	is.Equal("fatal", SentryLevels.Map(slog.LevelError+1))
+	is.Equal("notice", DatadogLevels.Map(slog.LevelInfo+2))
+	is.Equal("critical", DatadogLevels.Map(slog.LevelError+4))
+
+	is.Equal(1, OTelLevels.Map(slog.LevelDebug-4))
+	is.Equal(5, OTelLevels.Map(slog.LevelDebug))
+	is.Equal(9, OTelLevels.Map(slog.LevelInfo))
+	is.Equal(10, OTelLevels.Map(slog.LevelInfo+1))
+	is.Equal(13, OTelLevels.Map(slog.LevelWarn))
+	is.Equal(17, OTelLevels.Map(slog.LevelError))
+	is.Equal(21, OTelLevels.Map(slog.LevelError+4))
+	is.Equal(1, OTelLevels.Map(-100))
+	is.Equal(24, OTelLevels.Map(100))
+
+	is.Equal("dpanic", ZapLevels.Map(slog.LevelError+4))
+	is.Equal("trace", ZerologLevels.Map(slog.LevelDebug-5))
+	is.Equal("debug", ZerologLevels.Map(slog.LevelDebug-3))
+	is.Equal("warning", LogrusLevels.Map(slog.LevelWarn))
+
+	// reverse
+	level, ok := LogrusLevels.Level("warning")
+	is.True(ok)
+	is.Equal(slog.LevelWarn, level)
+	level, ok = OTelLevels.Level(17)
+	is.True(ok)
+	is.Equal(slog.LevelError, level)
+	level, ok = SyslogLevels.Level(0)
+	is.True(ok)
+	is.Equal(slog.LevelError+12, level)
+}
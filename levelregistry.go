@@ -0,0 +1,214 @@
+package slogcommon
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	LevelTrace  = slog.Level(-8)
+	LevelNotice = slog.Level(2)
+	LevelFatal  = slog.Level(12)
+)
+
+// DefaultLevelRegistry holds the slog levels, TRACE, NOTICE and FATAL.
+var DefaultLevelRegistry = mustNewLevelRegistry(map[string]slog.Level{
+	"TRACE":  LevelTrace,
+	"NOTICE": LevelNotice,
+	"FATAL":  LevelFatal,
+})
+
+// LevelRegistry names levels. Levels without a name are rendered relative to the
+// closest lower named level, like slog.Level.String does: "INFO+1".
+type LevelRegistry struct {
+	mu     sync.RWMutex
+	names  map[slog.Level]string
+	levels map[string]slog.Level
+	sorted []slog.Level
+}
+
+// NewLevelRegistry returns a registry holding the slog levels and custom levels.
+func NewLevelRegistry(custom map[string]slog.Level) (*LevelRegistry, error) {
+	r := &LevelRegistry{
+		names:  map[slog.Level]string{},
+		levels: map[string]slog.Level{},
+	}
+
+	for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		_ = r.Register(level.String(), level)
+	}
+
+	return r.register(custom)
+}
+
+func mustNewLevelRegistry(custom map[string]slog.Level) *LevelRegistry {
+	r, err := NewLevelRegistry(custom)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// With returns a copy of the registry holding custom levels as well.
+func (r *LevelRegistry) With(custom map[string]slog.Level) (*LevelRegistry, error) {
+	r.mu.RLock()
+	clone := &LevelRegistry{
+		names:  make(map[slog.Level]string, len(r.names)),
+		levels: make(map[string]slog.Level, len(r.levels)),
+		sorted: append([]slog.Level{}, r.sorted...),
+	}
+	for level, name := range r.names {
+		clone.names[level] = name
+	}
+	for name, level := range r.levels {
+		clone.levels[name] = level
+	}
+	r.mu.RUnlock()
+
+	return clone.register(custom)
+}
+
+// register adds custom levels in name order, so that the rendered name of a level
+// having several names is deterministic.
+func (r *LevelRegistry) register(custom map[string]slog.Level) (*LevelRegistry, error) {
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := r.Register(name, custom[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Register names a level. Names are case-insensitive, rendered in upper case and
+// must not be numbers nor contain '+' or '-'. A level has a single name: the last
+// one wins for rendering, while all names are accepted by ParseLevel. Registering
+// a name again moves it to the new level.
+func (r *LevelRegistry) Register(name string, level slog.Level) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, "+-") {
+		return fmt.Errorf("slog: invalid level name %q", name)
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return fmt.Errorf("slog: invalid level name %q", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if previous, ok := r.levels[name]; ok && previous != level && r.names[previous] == name {
+		delete(r.levels, name)
+		r.unnameLocked(previous)
+	}
+
+	if _, ok := r.names[level]; !ok {
+		r.sorted = append(r.sorted, level)
+		sort.Slice(r.sorted, func(i, j int) bool { return r.sorted[i] < r.sorted[j] })
+	}
+
+	r.names[level] = name
+	r.levels[name] = level
+
+	return nil
+}
+
+// unnameLocked renders level with one of its remaining names, if any.
+func (r *LevelRegistry) unnameLocked(level slog.Level) {
+	aliases := []string{}
+	for name, l := range r.levels {
+		if l == level {
+			aliases = append(aliases, name)
+		}
+	}
+
+	if len(aliases) > 0 {
+		sort.Strings(aliases)
+		r.names[level] = aliases[0]
+		return
+	}
+
+	delete(r.names, level)
+	r.sorted = slices.DeleteFunc(r.sorted, func(l slog.Level) bool { return l == level })
+}
+
+func (r *LevelRegistry) Name(level slog.Level) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if name, ok := r.names[level]; ok {
+		return name
+	}
+	if len(r.sorted) == 0 {
+		return strconv.Itoa(int(level))
+	}
+
+	// closest lower named level, or the lowest one
+	i := sort.Search(len(r.sorted), func(i int) bool { return r.sorted[i] > level }) - 1
+	if i < 0 {
+		i = 0
+	}
+	base := r.sorted[i]
+
+	return fmt.Sprintf("%s%+d", r.names[base], int(level-base))
+}
+
+// ParseLevel accepts names, numbers and names with offsets ("info+2", "TRACE-1"),
+// case-insensitively.
+func (r *LevelRegistry) ParseLevel(s string) (slog.Level, error) {
+	s = strings.TrimSpace(s)
+
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), nil
+	}
+
+	name, offset := s, 0
+	if i := strings.IndexAny(s, "+-"); i > 0 {
+		n, err := strconv.Atoi(s[i:])
+		if err != nil {
+			return 0, fmt.Errorf("slog: invalid level %q: %w", s, err)
+		}
+		name, offset = s[:i], n
+	}
+
+	r.mu.RLock()
+	level, ok := r.levels[strings.ToUpper(strings.TrimSpace(name))]
+	r.mu.RUnlock()
+
+	if !ok {
+		return 0, fmt.Errorf("slog: unknown level %q", s)
+	}
+
+	return level + slog.Level(offset), nil
+}
+
+// ReplaceAttr returns a ReplaceAttrFn rendering the level of records by name.
+func (r *LevelRegistry) ReplaceAttr() ReplaceAttrFn {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 || a.Key != slog.LevelKey {
+			return a
+		}
+
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(a.Key, r.Name(level))
+		}
+
+		return a
+	}
+}
+
+// ParseLevel parses a level with DefaultLevelRegistry.
+func ParseLevel(s string) (slog.Level, error) {
+	return DefaultLevelRegistry.ParseLevel(s)
+}
@@ -0,0 +1,122 @@
+package slogcommon
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelRegistryName(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	r := DefaultLevelRegistry
+	is.Equal("TRACE", r.Name(LevelTrace))
+	is.Equal("TRACE-2", r.Name(LevelTrace-2))
+	is.Equal("TRACE+1", r.Name(LevelTrace+1))
+	is.Equal("DEBUG", r.Name(slog.LevelDebug))
+	is.Equal("INFO+1", r.Name(slog.LevelInfo+1))
+	is.Equal("NOTICE", r.Name(LevelNotice))
+	is.Equal("NOTICE+1", r.Name(LevelNotice+1))
+	is.Equal("ERROR+3", r.Name(slog.LevelError+3))
+	is.Equal("FATAL", r.Name(LevelFatal))
+	is.Equal("FATAL+4", r.Name(LevelFatal+4))
+
+	r, err := NewLevelRegistry(nil)
+	is.NoError(err)
+	is.Equal("INFO+2", r.Name(LevelNotice))
+	is.NoError(r.Register("notice", LevelNotice))
+	is.Equal("NOTICE", r.Name(LevelNotice))
+	is.Equal("DEBUG-4", r.Name(LevelTrace))
+
+	// moving a name drops its previous level
+	is.NoError(r.Register("notice", LevelNotice+1))
+	is.Equal("INFO+2", r.Name(LevelNotice))
+	is.Equal("NOTICE", r.Name(LevelNotice+1))
+	level, err := r.ParseLevel("NOTICE")
+	is.NoError(err)
+	is.Equal(LevelNotice+1, level)
+
+	// aliases take over
+	is.NoError(r.Register("audit", LevelNotice+1))
+	is.NoError(r.Register("notice", LevelNotice+1))
+	is.Equal("NOTICE", r.Name(LevelNotice+1))
+	is.NoError(r.Register("notice", LevelNotice))
+	is.Equal("AUDIT", r.Name(LevelNotice+1))
+	is.Equal("NOTICE", r.Name(LevelNotice))
+
+	for _, name := range []string{"", " ", "info+1", "a-b", "42"} {
+		is.Error(r.Register(name, 1), name)
+	}
+	_, err = NewLevelRegistry(map[string]slog.Level{"a+b": 1})
+	is.Error(err)
+
+	// With leaves the original untouched
+	custom, err := DefaultLevelRegistry.With(map[string]slog.Level{"audit": 3})
+	is.NoError(err)
+	is.Equal("AUDIT", custom.Name(3))
+	is.Equal("FATAL", custom.Name(LevelFatal))
+	is.Equal("NOTICE+1", DefaultLevelRegistry.Name(3))
+
+	is.Equal("7", (&LevelRegistry{}).Name(7))
+}
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	for input, expected := range map[string]slog.Level{
+		"trace":    LevelTrace,
+		"TRACE":    LevelTrace,
+		" Notice ": LevelNotice,
+		"fatal":    LevelFatal,
+		"info":     slog.LevelInfo,
+		"INFO+2":   slog.LevelInfo + 2,
+		"warn-1":   slog.LevelWarn - 1,
+		"Error+4":  slog.LevelError + 4,
+		"12":       12,
+		"-8":       -8,
+		"+3":       3,
+	} {
+		level, err := ParseLevel(input)
+		is.NoError(err, input)
+		is.Equal(expected, level, input)
+	}
+
+	for _, input := range []string{"", "verbose", "info+", "info+x", "+"} {
+		_, err := ParseLevel(input)
+		is.Error(err, input)
+	}
+}
+
+func TestLevelRegistryReplaceAttr(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level:       LevelTrace,
+		ReplaceAttr: DefaultLevelRegistry.ReplaceAttr(),
+	}))
+
+	logger.Log(context.Background(), LevelTrace, "a", "level", 1)
+	logger.Log(context.Background(), LevelFatal, "b")
+	logger.Log(context.Background(), slog.LevelInfo+1, "c")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	is.Len(lines, 3)
+	is.Contains(lines[0], "level=TRACE msg=a level=1")
+	is.Contains(lines[1], "level=FATAL msg=b")
+	is.Contains(lines[2], "level=INFO+1 msg=c")
+
+	// round trip
+	for level := slog.Level(-12); level <= 16; level++ {
+		parsed, err := ParseLevel(DefaultLevelRegistry.Name(level))
+		is.NoError(err)
+		is.Equal(level, parsed)
+	}
+}
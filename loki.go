@@ -0,0 +1,181 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const lokiDefaultMaxLabelValueLength = 1024
+
+type LokiLabelRule struct {
+	// Path to the attribute: groups followed by the key, e.g. []string{"http", "method"}.
+	Path []string
+	// Label name; defaults to the sanitized path joined with "_".
+	Name string
+}
+
+type LokiLabelOptions struct {
+	Rules []LokiLabelRule
+	// MaxValueLength truncates label values (in runes). Defaults to 1024.
+	MaxValueLength int
+	// Deny rejects label names considered high-cardinality (e.g. "id", "request_id"),
+	// matched against the label name and its last "_" separated segment.
+	Deny []string
+	// MaxDistinctValues rejects a label once it has seen more distinct values than
+	// this limit (0 disables the guard). Requires a shared *LokiLabelExtractor.
+	MaxDistinctValues int
+}
+
+var DefaultLokiDenyLabels = []string{"id", "uuid", "trace_id", "span_id", "request_id", "user_id", "session_id"}
+
+type LokiLabelExtractor struct {
+	opts LokiLabelOptions
+	mu   sync.Mutex
+	seen map[string]map[string]struct{}
+}
+
+func NewLokiLabelExtractor(opts LokiLabelOptions) *LokiLabelExtractor {
+	if opts.MaxValueLength <= 0 {
+		opts.MaxValueLength = lokiDefaultMaxLabelValueLength
+	}
+	if opts.Deny == nil {
+		opts.Deny = DefaultLokiDenyLabels
+	}
+
+	return &LokiLabelExtractor{
+		opts: opts,
+		seen: map[string]map[string]struct{}{},
+	}
+}
+
+// Extract selects the configured attributes as stream labels and returns the
+// remaining attributes for the log line.
+func (e *LokiLabelExtractor) Extract(attrs []slog.Attr) (map[string]string, []slog.Attr) {
+	labels := map[string]string{}
+
+	for _, rule := range e.opts.Rules {
+		if len(rule.Path) == 0 {
+			continue
+		}
+
+		groups, key := rule.Path[:len(rule.Path)-1], rule.Path[len(rule.Path)-1]
+		attr, ok := FindAttrByGroupAndKey(attrs, groups, key)
+		if !ok || attr.Value.Resolve().Kind() == slog.KindGroup {
+			continue
+		}
+
+		name := rule.Name
+		if name == "" {
+			name = strings.Join(rule.Path, "_")
+		}
+		name = LokiLabelName(name)
+
+		if e.isDenied(name) {
+			continue
+		}
+
+		value := truncateRunes(ValueToString(attr.Value), e.opts.MaxValueLength)
+		if !e.admit(name, value) {
+			continue
+		}
+
+		labels[name] = value
+		attrs = removeAttrByPath(attrs, groups, key)
+	}
+
+	return labels, attrs
+}
+
+func (e *LokiLabelExtractor) isDenied(name string) bool {
+	last := name
+	if i := strings.LastIndexByte(name, '_'); i >= 0 {
+		last = name[i+1:]
+	}
+
+	for _, deny := range e.opts.Deny {
+		if strings.EqualFold(name, deny) || strings.EqualFold(last, deny) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *LokiLabelExtractor) admit(name string, value string) bool {
+	if e.opts.MaxDistinctValues <= 0 {
+		return true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	values, ok := e.seen[name]
+	if !ok {
+		values = map[string]struct{}{}
+		e.seen[name] = values
+	}
+
+	if _, ok := values[value]; ok {
+		return true
+	}
+
+	if len(values) >= e.opts.MaxDistinctValues {
+		return false
+	}
+
+	values[value] = struct{}{}
+	return true
+}
+
+// LokiStreamKey returns a stable identifier of a label set, in Prometheus notation:
+// {a="1", b="2"}. Records sharing a key can be batched into the same stream.
+func LokiStreamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(labels[k]))
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+// LokiLabelName sanitizes a name with PrometheusLabelName, since Loki follows
+// the Prometheus label name rules.
+func LokiLabelName(name string) string {
+	return PrometheusLabelName(name)
+}
+
+func removeAttrByPath(attrs []slog.Attr, groups []string, key string) []slog.Attr {
+	if len(groups) == 0 {
+		return removeAttrsByKey(attrs, key)
+	}
+
+	output := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Key == groups[0] && attr.Value.Kind() == slog.KindGroup {
+			children := removeAttrByPath(attr.Value.Group(), groups[1:], key)
+			if len(children) == 0 {
+				continue
+			}
+			attr = slog.Attr{Key: attr.Key, Value: slog.GroupValue(children...)}
+		}
+		output = append(output, attr)
+	}
+
+	return output
+}
@@ -0,0 +1,84 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLokiLabelExtractor(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	extractor := NewLokiLabelExtractor(LokiLabelOptions{
+		Rules: []LokiLabelRule{
+			{Path: []string{"service"}},
+			{Path: []string{"http", "method"}},
+			{Path: []string{"http", "user-agent"}, Name: "ua"},
+			{Path: []string{"request_id"}},
+			{Path: []string{"missing"}},
+		},
+		MaxValueLength: 5,
+	})
+
+	attrs := []slog.Attr{
+		slog.String("service", "api"),
+		slog.String("request_id", "abc"),
+		slog.Group("http", slog.String("method", "GET"), slog.String("user-agent", "curl/8.0")),
+		slog.String("foo", "bar"),
+	}
+
+	labels, rest := extractor.Extract(attrs)
+	is.Equal(map[string]string{"service": "api", "http_method": "GET", "ua": "curl/"}, labels)
+	is.Equal([]slog.Attr{slog.String("request_id", "abc"), slog.String("foo", "bar")}, rest)
+	is.Len(attrs, 4)
+
+	labels, rest = extractor.Extract(attrs[1:])
+	is.Empty(labels["service"])
+	is.Len(rest, 2)
+}
+
+func TestLokiLabelExtractorCardinality(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	extractor := NewLokiLabelExtractor(LokiLabelOptions{
+		Rules:             []LokiLabelRule{{Path: []string{"tenant"}}},
+		MaxDistinctValues: 2,
+	})
+
+	labels, _ := extractor.Extract([]slog.Attr{slog.String("tenant", "a")})
+	is.Equal("a", labels["tenant"])
+	labels, _ = extractor.Extract([]slog.Attr{slog.String("tenant", "b")})
+	is.Equal("b", labels["tenant"])
+	labels, rest := extractor.Extract([]slog.Attr{slog.String("tenant", "c")})
+	is.Empty(labels)
+	is.Equal([]slog.Attr{slog.String("tenant", "c")}, rest)
+	labels, _ = extractor.Extract([]slog.Attr{slog.String("tenant", "a")})
+	is.Equal("a", labels["tenant"])
+}
+
+func TestLokiStreamKey(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal(`{}`, LokiStreamKey(nil))
+	is.Equal(`{a="1", b="x\"y"}`, LokiStreamKey(map[string]string{"b": `x"y`, "a": "1"}))
+	is.Equal(
+		LokiStreamKey(map[string]string{"a": "1", "b": "2", "c": "3"}),
+		LokiStreamKey(map[string]string{"c": "3", "b": "2", "a": "1"}),
+	)
+}
+
+func TestLokiLabelName(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("http_method", LokiLabelName("http_method"))
+	is.Equal("http_user_agent", LokiLabelName("http.user-agent"))
+	is.Equal("_1abc", LokiLabelName("1abc"))
+	is.Equal("label__name__", LokiLabelName("__name__"))
+	is.Equal("_", LokiLabelName(""))
+	is.Equal("caf__", LokiLabelName("café"))
+}
@@ -0,0 +1,315 @@
+package slogcommon
+
+import (
+	"encoding/binary"
+	"log/slog"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// maxEncodeDepth bounds group nesting and the reflection based encoding of
+// KindAny values. Deeper groups are replaced by NormalizerMaxDepthMarker.
+const maxEncodeDepth = 32
+
+// binaryEncoder abstracts the few primitives shared by MessagePack and CBOR, so
+// that slog values are walked once for both formats.
+type binaryEncoder interface {
+	appendNil(dst []byte) []byte
+	appendBool(dst []byte, b bool) []byte
+	appendInt(dst []byte, i int64) []byte
+	appendUint(dst []byte, u uint64) []byte
+	appendFloat(dst []byte, f float64) []byte
+	appendString(dst []byte, s string) []byte
+	appendBytes(dst []byte, b []byte) []byte
+	appendTime(dst []byte, t time.Time) []byte
+	appendArrayHeader(dst []byte, n int) []byte
+	appendMapHeader(dst []byte, n int) []byte
+}
+
+// AppendMsgpack encodes attributes as a MessagePack map. Groups become nested maps,
+// times use the timestamp extension (-1) and durations are int64 nanoseconds.
+func AppendMsgpack(dst []byte, attrs ...slog.Attr) []byte {
+	return appendEncodedAttrs(msgpackEncoder{}, dst, attrs, 0)
+}
+
+func appendEncodedAttrs(enc binaryEncoder, dst []byte, attrs []slog.Attr, depth int) []byte {
+	merged := mergeAttrsByKey(attrs)
+
+	dst = enc.appendMapHeader(dst, len(merged))
+	for _, attr := range merged {
+		dst = enc.appendString(dst, attr.Key)
+		dst = appendEncodedValue(enc, dst, attr.Value, depth)
+	}
+
+	return dst
+}
+
+func appendEncodedValue(enc binaryEncoder, dst []byte, v slog.Value, depth int) []byte {
+	v = v.Resolve()
+
+	switch v.Kind() {
+	case slog.KindInt64:
+		return enc.appendInt(dst, v.Int64())
+	case slog.KindUint64:
+		return enc.appendUint(dst, v.Uint64())
+	case slog.KindFloat64:
+		return enc.appendFloat(dst, v.Float64())
+	case slog.KindBool:
+		return enc.appendBool(dst, v.Bool())
+	case slog.KindDuration:
+		return enc.appendInt(dst, int64(v.Duration()))
+	case slog.KindTime:
+		return enc.appendTime(dst, v.Time())
+	case slog.KindString:
+		return enc.appendString(dst, v.String())
+	case slog.KindGroup:
+		// Resolve bounds LogValuer chains, recursive groups are bounded here
+		if depth >= maxEncodeDepth {
+			return enc.appendString(dst, NormalizerMaxDepthMarker)
+		}
+		return appendEncodedAttrs(enc, dst, v.Group(), depth+1)
+	default:
+		return appendEncodedAny(enc, dst, v.Any(), depth)
+	}
+}
+
+func appendEncodedAny(enc binaryEncoder, dst []byte, value any, depth int) []byte {
+	switch x := value.(type) {
+	case nil:
+		return enc.appendNil(dst)
+	case []byte:
+		return enc.appendBytes(dst, x)
+	case error:
+		return enc.appendString(dst, x.Error())
+	case time.Time:
+		return enc.appendTime(dst, x)
+	case time.Duration:
+		return enc.appendInt(dst, int64(x))
+	case slog.Value:
+		return appendEncodedValue(enc, dst, x, depth)
+	case []slog.Attr:
+		if depth >= maxEncodeDepth {
+			return enc.appendString(dst, NormalizerMaxDepthMarker)
+		}
+		return appendEncodedAttrs(enc, dst, x, depth+1)
+	}
+
+	if depth >= maxEncodeDepth {
+		return enc.appendString(dst, AnyValueToString(slog.AnyValue(value)))
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return enc.appendBool(dst, rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return enc.appendInt(dst, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return enc.appendUint(dst, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return enc.appendFloat(dst, rv.Float())
+	case reflect.String:
+		return enc.appendString(dst, rv.String())
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return enc.appendNil(dst)
+		}
+		if rv.Kind() == reflect.Interface || rv.Elem().Kind() != reflect.Struct {
+			return appendEncodedAny(enc, dst, rv.Elem().Interface(), depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return enc.appendNil(dst)
+		}
+		dst = enc.appendArrayHeader(dst, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			dst = appendEncodedAny(enc, dst, rv.Index(i).Interface(), depth+1)
+		}
+		return dst
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return enc.appendNil(dst)
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		dst = enc.appendMapHeader(dst, len(keys))
+		for _, key := range keys {
+			dst = enc.appendString(dst, key.String())
+			dst = appendEncodedAny(enc, dst, rv.MapIndex(key).Interface(), depth+1)
+		}
+		return dst
+	}
+
+	return enc.appendString(dst, AnyValueToString(slog.AnyValue(value)))
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) appendNil(dst []byte) []byte {
+	return append(dst, 0xc0)
+}
+
+func (msgpackEncoder) appendBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, 0xc3)
+	}
+	return append(dst, 0xc2)
+}
+
+func (e msgpackEncoder) appendInt(dst []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return e.appendUint(dst, uint64(i))
+	case i >= -32:
+		return append(dst, byte(i))
+	case i >= math.MinInt8:
+		return append(dst, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(i))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(i))
+	}
+}
+
+func (msgpackEncoder) appendUint(dst []byte, u uint64) []byte {
+	switch {
+	case u <= math.MaxInt8:
+		return append(dst, byte(u))
+	case u <= math.MaxUint8:
+		return append(dst, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(u))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), u)
+	}
+}
+
+func (msgpackEncoder) appendFloat(dst []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(dst, 0xcb), math.Float64bits(f))
+}
+
+func (msgpackEncoder) appendString(dst []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(n))
+	}
+	return append(dst, s...)
+}
+
+func (msgpackEncoder) appendBytes(dst []byte, b []byte) []byte {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xc5), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xc6), uint32(n))
+	}
+	return append(dst, b...)
+}
+
+// appendTime uses the timestamp extension type (-1), picking the smallest of the
+// 32, 64 and 96-bit formats.
+func (msgpackEncoder) appendTime(dst []byte, t time.Time) []byte {
+	sec := t.Unix()
+	nsec := uint64(t.Nanosecond())
+
+	switch {
+	case sec >= 0 && sec <= math.MaxUint32 && nsec == 0:
+		dst = append(dst, 0xd6, 0xff)
+		return binary.BigEndian.AppendUint32(dst, uint32(sec))
+	case sec >= 0 && sec < 1<<34:
+		dst = append(dst, 0xd7, 0xff)
+		return binary.BigEndian.AppendUint64(dst, nsec<<34|uint64(sec))
+	default:
+		dst = append(dst, 0xc7, 12, 0xff)
+		dst = binary.BigEndian.AppendUint32(dst, uint32(nsec))
+		return binary.BigEndian.AppendUint64(dst, uint64(sec))
+	}
+}
+
+func (msgpackEncoder) appendArrayHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, 0xdd), uint32(n))
+	}
+}
+
+func (msgpackEncoder) appendMapHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, 0xdf), uint32(n))
+	}
+}
+
+type FluentdEntry struct {
+	Time  time.Time
+	Attrs []slog.Attr
+}
+
+type FluentdForwardOptions struct {
+	// Chunk requests an acknowledgment from the server (at-least-once delivery).
+	Chunk string
+}
+
+// AppendFluentdForward encodes a Fluentd Forward mode message:
+// [tag, [[time, record], ...], option]. Times use the Fluentd EventTime extension (0).
+func AppendFluentdForward(dst []byte, tag string, entries []FluentdEntry, opts FluentdForwardOptions) []byte {
+	enc := msgpackEncoder{}
+
+	dst = enc.appendArrayHeader(dst, 3)
+	dst = enc.appendString(dst, tag)
+	dst = enc.appendArrayHeader(dst, len(entries))
+	for _, entry := range entries {
+		dst = enc.appendArrayHeader(dst, 2)
+		dst = AppendFluentdEventTime(dst, entry.Time)
+		dst = appendEncodedAttrs(enc, dst, entry.Attrs, 0)
+	}
+
+	options := 1
+	if opts.Chunk != "" {
+		options++
+	}
+	dst = enc.appendMapHeader(dst, options)
+	dst = enc.appendString(dst, "size")
+	dst = enc.appendInt(dst, int64(len(entries)))
+	if opts.Chunk != "" {
+		dst = enc.appendString(dst, "chunk")
+		dst = enc.appendString(dst, opts.Chunk)
+	}
+
+	return dst
+}
+
+// AppendFluentdEventTime encodes a time as the Fluentd EventTime extension:
+// fixext8 with type 0, seconds and nanoseconds as big-endian uint32.
+func AppendFluentdEventTime(dst []byte, t time.Time) []byte {
+	dst = append(dst, 0xd7, 0x00)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(dst, uint32(t.Nanosecond()))
+}
@@ -0,0 +1,97 @@
+package slogcommon
+
+import (
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgpackEncoder(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	enc := msgpackEncoder{}
+	h := func(b []byte) string { return hex.EncodeToString(b) }
+
+	is.Equal("c0", h(enc.appendNil(nil)))
+	is.Equal("c3", h(enc.appendBool(nil, true)))
+	is.Equal("c2", h(enc.appendBool(nil, false)))
+	is.Equal("7f", h(enc.appendInt(nil, 127)))
+	is.Equal("cc80", h(enc.appendInt(nil, 128)))
+	is.Equal("cd0100", h(enc.appendInt(nil, 256)))
+	is.Equal("ce00010000", h(enc.appendInt(nil, 65536)))
+	is.Equal("cf0000000100000000", h(enc.appendInt(nil, 1<<32)))
+	is.Equal("ff", h(enc.appendInt(nil, -1)))
+	is.Equal("e0", h(enc.appendInt(nil, -32)))
+	is.Equal("d0df", h(enc.appendInt(nil, -33)))
+	is.Equal("d1ff7f", h(enc.appendInt(nil, -129)))
+	is.Equal("d2ffff7fff", h(enc.appendInt(nil, -32769)))
+	is.Equal("d38000000000000000", h(enc.appendInt(nil, math.MinInt64)))
+	is.Equal("cfffffffffffffffff", h(enc.appendUint(nil, math.MaxUint64)))
+	is.Equal("cb3ff8000000000000", h(enc.appendFloat(nil, 1.5)))
+	is.Equal("a3666f6f", h(enc.appendString(nil, "foo")))
+	is.Equal("d920"+strings.Repeat("61", 32), h(enc.appendString(nil, strings.Repeat("a", 32))))
+	is.Equal("c4020102", h(enc.appendBytes(nil, []byte{1, 2})))
+	is.Equal("93", h(enc.appendArrayHeader(nil, 3)))
+	is.Equal("dc0010", h(enc.appendArrayHeader(nil, 16)))
+	is.Equal("81", h(enc.appendMapHeader(nil, 1)))
+	is.Equal("de0010", h(enc.appendMapHeader(nil, 16)))
+
+	// timestamp 32, 64 and 96
+	is.Equal("d6ff00000001", h(enc.appendTime(nil, time.Unix(1, 0))))
+	is.Equal("d7ff0000000400000001", h(enc.appendTime(nil, time.Unix(1, 1))))
+	is.Equal("c70cff00000000ffffffffffffffff", h(enc.appendTime(nil, time.Unix(-1, 0))))
+}
+
+func TestAppendMsgpack(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	output := AppendMsgpack(nil,
+		slog.String("a", "b"),
+		slog.Duration("d", time.Microsecond),
+		slog.Group("g", slog.Bool("t", true)),
+		slog.Group("g", slog.Any("n", nil)),
+		slog.Any("l", []any{1, "x"}),
+		slog.Any("m", map[string]int{"z": 1, "y": 2}),
+		slog.Any("e", assert.AnError),
+	)
+
+	is.Equal(
+		"86"+
+			"a161"+"a162"+
+			"a164"+"cd03e8"+
+			"a167"+"82"+"a174c3"+"a16ec0"+
+			"a16c"+"92"+"01"+"a178"+
+			"a16d"+"82"+"a17902"+"a17a01"+
+			"a165"+hex.EncodeToString(msgpackEncoder{}.appendString(nil, assert.AnError.Error())),
+		hex.EncodeToString(output),
+	)
+
+	// recursive LogValuers are cut off
+	output = AppendMsgpack(nil, slog.Any("x", testRecursiveLogValuer{}))
+	is.Contains(string(output), NormalizerMaxDepthMarker)
+}
+
+func TestAppendFluentdForward(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	entries := []FluentdEntry{
+		{Time: time.Unix(1, 2), Attrs: []slog.Attr{slog.String("msg", "hi")}},
+	}
+
+	is.Equal(
+		"93"+"a3617070"+"91"+"92"+"d7000000000100000002"+"81a36d7367a26869"+"81a473697a6501",
+		hex.EncodeToString(AppendFluentdForward(nil, "app", entries, FluentdForwardOptions{})),
+	)
+	is.Equal(
+		"93"+"a3617070"+"90"+"82a473697a6500a56368756e6ba3616263",
+		hex.EncodeToString(AppendFluentdForward(nil, "app", nil, FluentdForwardOptions{Chunk: "abc"})),
+	)
+}
@@ -0,0 +1,341 @@
+package slogcommon
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	valueNormalizerDefaultMaxDepth = 10
+
+	NormalizerCycleMarker    = "<cycle>"
+	NormalizerMaxDepthMarker = "<max depth>"
+)
+
+type ValueNormalizerOptions struct {
+	// MaxDepth bounds the nesting of the produced tree. Defaults to 10.
+	MaxDepth int
+}
+
+// ValueNormalizer converts KindAny values into JSON compatible trees made of
+// map[string]any, []any, string, bool, int64, uint64, float64 and nil.
+//
+// For each value, the first matching rule applies: a registered converter,
+// json.Marshaler, encoding.TextMarshaler, error, fmt.Stringer, then reflection.
+type ValueNormalizer struct {
+	maxDepth int
+
+	mu         sync.RWMutex
+	converters map[reflect.Type]func(any) any
+}
+
+func NewValueNormalizer(opts ValueNormalizerOptions) *ValueNormalizer {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = valueNormalizerDefaultMaxDepth
+	}
+
+	return &ValueNormalizer{
+		maxDepth:   opts.MaxDepth,
+		converters: map[reflect.Type]func(any) any{},
+	}
+}
+
+// RegisterValueConverter registers a converter for values of type T. The output
+// of the converter is normalized again.
+func RegisterValueConverter[T any](n *ValueNormalizer, fn func(T) any) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.converters[typ] = func(v any) any {
+		return fn(v.(T))
+	}
+}
+
+func (n *ValueNormalizer) Normalize(v any) any {
+	return n.normalize(v, 0, map[uintptr]struct{}{})
+}
+
+func (n *ValueNormalizer) NormalizeValue(v slog.Value) any {
+	return n.normalizeValue(v, 0, map[uintptr]struct{}{})
+}
+
+// AttrsToMap follows the AttrsToMap semantics, with normalized KindAny values.
+func (n *ValueNormalizer) AttrsToMap(attrs ...slog.Attr) map[string]any {
+	return n.attrsToMap(attrs, 0, map[uintptr]struct{}{})
+}
+
+// ReplaceAttr returns a ReplaceAttrFn converting KindAny values.
+func (n *ValueNormalizer) ReplaceAttr() ReplaceAttrFn {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindAny {
+			a.Value = slog.AnyValue(n.Normalize(v.Any()))
+		}
+		return a
+	}
+}
+
+func (n *ValueNormalizer) converter(typ reflect.Type) (func(any) any, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	fn, ok := n.converters[typ]
+	return fn, ok
+}
+
+func (n *ValueNormalizer) normalize(v any, depth int, visiting map[uintptr]struct{}) any {
+	if v == nil {
+		return nil
+	}
+	if depth > n.maxDepth {
+		return NormalizerMaxDepthMarker
+	}
+
+	if fn, ok := n.converter(reflect.TypeOf(v)); ok {
+		return n.normalize(fn(v), depth+1, visiting)
+	}
+
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return nil
+	}
+
+	switch x := v.(type) {
+	case slog.LogValuer:
+		if rv.Kind() == reflect.Pointer {
+			ptr := rv.Pointer()
+			if _, ok := visiting[ptr]; ok {
+				return NormalizerCycleMarker
+			}
+			visiting[ptr] = struct{}{}
+			defer delete(visiting, ptr)
+		}
+		return n.normalizeValue(x.LogValue().Resolve(), depth, visiting)
+	case slog.Value:
+		return n.normalizeValue(x, depth, visiting)
+	case json.Marshaler:
+		return normalizeJSONMarshaler(x)
+	case encoding.TextMarshaler:
+		data, err := x.MarshalText()
+		if err != nil {
+			return nil
+		}
+		return string(data)
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	case []byte:
+		return base64.StdEncoding.EncodeToString(x)
+	}
+
+	return n.normalizeReflect(rv, depth, visiting)
+}
+
+func (n *ValueNormalizer) normalizeValue(v slog.Value, depth int, visiting map[uintptr]struct{}) any {
+	if depth > n.maxDepth {
+		return NormalizerMaxDepthMarker
+	}
+
+	switch v.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		// LogValuers are resolved by normalize, which detects cycles
+		return n.normalize(v.Any(), depth, visiting)
+	case slog.KindTime, slog.KindDuration:
+		// same output as a time.Time or time.Duration wrapped in KindAny
+		return n.normalize(v.Any(), depth, visiting)
+	case slog.KindGroup:
+		return n.attrsToMap(v.Group(), depth, visiting)
+	default:
+		_, value := AttrToValue(slog.Attr{Value: v})
+		return value
+	}
+}
+
+func (n *ValueNormalizer) attrsToMap(attrs []slog.Attr, depth int, visiting map[uintptr]struct{}) map[string]any {
+	output := map[string]any{}
+	seen := make(map[string]int, len(attrs))
+	for _, attr := range attrs {
+		seen[attr.Key]++
+	}
+
+	for _, attr := range attrs {
+		if seen[attr.Key] > 1 {
+			// duplicated keys are merged below
+			continue
+		}
+		output[attr.Key] = n.normalizeValue(attr.Value, depth+1, visiting)
+	}
+
+	if len(output) < len(seen) {
+		// mergeAttrsByKey resolves values, so it is kept for duplicated keys
+		for _, attr := range mergeAttrsByKey(attrs) {
+			if seen[attr.Key] > 1 {
+				output[attr.Key] = n.normalizeValue(attr.Value, depth+1, visiting)
+			}
+		}
+	}
+
+	return output
+}
+
+func (n *ValueNormalizer) normalizeReflect(rv reflect.Value, depth int, visiting map[uintptr]struct{}) any {
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Kind() == reflect.Pointer {
+			ptr := rv.Pointer()
+			if _, ok := visiting[ptr]; ok {
+				return NormalizerCycleMarker
+			}
+			visiting[ptr] = struct{}{}
+			defer delete(visiting, ptr)
+		}
+		return n.normalize(rv.Elem().Interface(), depth, visiting)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice {
+			if rv.IsNil() {
+				return nil
+			}
+			if rv.Len() > 0 {
+				ptr := rv.Pointer()
+				if _, ok := visiting[ptr]; ok {
+					return NormalizerCycleMarker
+				}
+				visiting[ptr] = struct{}{}
+				defer delete(visiting, ptr)
+			}
+		}
+		output := make([]any, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			output = append(output, n.normalize(rv.Index(i).Interface(), depth+1, visiting))
+		}
+		return output
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		ptr := rv.Pointer()
+		if _, ok := visiting[ptr]; ok {
+			return NormalizerCycleMarker
+		}
+		visiting[ptr] = struct{}{}
+		defer delete(visiting, ptr)
+
+		output := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			output[normalizeMapKey(iter.Key())] = n.normalize(iter.Value().Interface(), depth+1, visiting)
+		}
+		return output
+	case reflect.Struct:
+		output := map[string]any{}
+		n.normalizeStructFields(rv, output, depth, visiting)
+		return output
+	default:
+		// chan, func, unsafe pointer, complex
+		return fmt.Sprintf("<%s>", rv.Type())
+	}
+}
+
+// normalizeStructFields follows the encoding/json field rules: exported fields only,
+// `json:"name,omitempty"` and `json:"-"` tags, untagged embedded structs flattened.
+func (n *ValueNormalizer) normalizeStructFields(rv reflect.Value, output map[string]any, depth int, visiting map[uintptr]struct{}) {
+	typ := rv.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		value := rv.Field(i)
+
+		if field.Anonymous && name == "" {
+			if value.Kind() == reflect.Struct {
+				n.normalizeStructFields(value, output, depth, visiting)
+				continue
+			}
+			if value.Kind() == reflect.Pointer && value.Type().Elem().Kind() == reflect.Struct {
+				if value.IsNil() {
+					continue
+				}
+				// embedded pointers may be cyclic: they count as a nesting level
+				ptr := value.Pointer()
+				if _, ok := visiting[ptr]; ok {
+					output[field.Name] = NormalizerCycleMarker
+					continue
+				}
+				if depth+1 > n.maxDepth {
+					output[field.Name] = NormalizerMaxDepthMarker
+					continue
+				}
+				visiting[ptr] = struct{}{}
+				n.normalizeStructFields(value.Elem(), output, depth+1, visiting)
+				delete(visiting, ptr)
+				continue
+			}
+		}
+
+		if !field.IsExported() || !value.CanInterface() {
+			continue
+		}
+		if strings.Contains(options, "omitempty") && value.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		output[name] = n.normalize(value.Interface(), depth+1, visiting)
+	}
+}
+
+func normalizeJSONMarshaler(m json.Marshaler) any {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return nil
+	}
+
+	var output any
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil
+	}
+
+	return output
+}
+
+func normalizeMapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if data, err := tm.MarshalText(); err == nil {
+			return string(data)
+		}
+	}
+
+	return fmt.Sprint(key.Interface())
+}
@@ -0,0 +1,169 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testNormalizerBase struct {
+	ID int `json:"id"`
+}
+
+type testNormalizerStruct struct {
+	testNormalizerBase
+	Name     string                `json:"name"`
+	Password string                `json:"-"`
+	Email    string                `json:"email,omitempty"`
+	Tags     []string              `json:"tags"`
+	Meta     map[string]any        `json:"meta"`
+	Labels   map[int]string        `json:"labels"`
+	Parent   *testNormalizerStruct `json:"parent,omitempty"`
+	private  string
+}
+
+type testNormalizerNode struct {
+	Name string
+	Next *testNormalizerNode
+}
+
+type testNormalizerSelfLogValuer struct{}
+
+func (l *testNormalizerSelfLogValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("self", l))
+}
+
+type testNormalizerEmbedded struct {
+	*testNormalizerEmbedded
+	X int
+}
+
+type testStringer struct{}
+
+func (testStringer) String() string { return "stringer" }
+
+type testJSONMarshaler struct{}
+
+func (testJSONMarshaler) MarshalJSON() ([]byte, error) { return []byte(`{"a":[1,"b"]}`), nil }
+
+func TestValueNormalizer(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	n := NewValueNormalizer(ValueNormalizerOptions{})
+
+	is.Nil(n.Normalize(nil))
+	is.Nil(n.Normalize((*testNormalizerStruct)(nil)))
+	is.Equal(int64(42), n.Normalize(42))
+	is.Equal(uint64(42), n.Normalize(uint8(42)))
+	is.Equal(1.5, n.Normalize(float32(1.5)))
+	is.Equal("foo", n.Normalize("foo"))
+	is.Equal(true, n.Normalize(true))
+	is.Equal("AQI=", n.Normalize([]byte{1, 2}))
+	is.Equal("stringer", n.Normalize(testStringer{}))
+	is.Equal("1s", n.Normalize(time.Second))
+	is.Equal(assert.AnError.Error(), n.Normalize(assert.AnError))
+	is.Equal("127.0.0.1", n.Normalize(net.ParseIP("127.0.0.1")))
+	is.Equal("2024-01-02T03:04:05Z", n.Normalize(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	is.Equal("1s", n.NormalizeValue(slog.DurationValue(time.Second)))
+	is.Equal("2024-01-02T03:04:05Z", n.NormalizeValue(slog.TimeValue(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))))
+	is.Equal(map[string]any{"a": []any{float64(1), "b"}}, n.Normalize(testJSONMarshaler{}))
+	is.Equal("<chan int>", n.Normalize(make(chan int)))
+	is.Equal(map[string]any{"name": "userName", "password": "********"}, n.Normalize(stubLogValuer))
+
+	value := &testNormalizerStruct{
+		testNormalizerBase: testNormalizerBase{ID: 1},
+		Name:               "john",
+		Password:           "secret",
+		Tags:               []string{"a"},
+		Meta:               map[string]any{"k": []int{1}},
+		Labels:             map[int]string{1: "one"},
+		Parent:             &testNormalizerStruct{Name: "root"},
+		private:            "hidden",
+	}
+	is.Equal(map[string]any{
+		"id":     int64(1),
+		"name":   "john",
+		"tags":   []any{"a"},
+		"meta":   map[string]any{"k": []any{int64(1)}},
+		"labels": map[string]any{"1": "one"},
+		"parent": map[string]any{
+			"id":     int64(0),
+			"name":   "root",
+			"tags":   nil,
+			"meta":   nil,
+			"labels": nil,
+		},
+	}, n.Normalize(value))
+}
+
+func TestValueNormalizerCyclesAndDepth(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	n := NewValueNormalizer(ValueNormalizerOptions{MaxDepth: 3})
+
+	node := &testNormalizerNode{Name: "a"}
+	node.Next = node
+	is.Equal(map[string]any{"Name": "a", "Next": "<cycle>"}, n.Normalize(node))
+
+	m := map[string]any{}
+	m["self"] = m
+	is.Equal(map[string]any{"self": "<cycle>"}, n.Normalize(m))
+
+	deep := []any{[]any{[]any{[]any{[]any{1}}}}}
+	is.Equal([]any{[]any{[]any{[]any{"<max depth>"}}}}, n.Normalize(deep))
+
+	is.Equal(map[string]any{"self": "<cycle>"}, n.Normalize(&testNormalizerSelfLogValuer{}))
+	recursive := map[string]any{"child": map[string]any{"child": map[string]any{"child": map[string]any{"child": "<max depth>"}}}}
+	is.Equal(recursive, n.Normalize(testRecursiveLogValuer{}))
+	is.Equal(recursive, n.NormalizeValue(slog.AnyValue(testRecursiveLogValuer{})))
+
+	embedded := &testNormalizerEmbedded{X: 1}
+	embedded.testNormalizerEmbedded = embedded
+	is.Equal(map[string]any{"X": int64(1), "testNormalizerEmbedded": "<cycle>"}, n.Normalize(embedded))
+
+	// shared but acyclic pointers are not cycles
+	shared := &testNormalizerNode{Name: "shared"}
+	is.Equal(
+		[]any{map[string]any{"Name": "shared", "Next": nil}, map[string]any{"Name": "shared", "Next": nil}},
+		n.Normalize([]*testNormalizerNode{shared, shared}),
+	)
+}
+
+func TestValueNormalizerConverters(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	n := NewValueNormalizer(ValueNormalizerOptions{})
+	RegisterValueConverter(n, func(node *testNormalizerNode) any {
+		return "node:" + node.Name
+	})
+	RegisterValueConverter(n, func(d time.Duration) any {
+		return d.Seconds()
+	})
+
+	is.Equal("node:a", n.Normalize(&testNormalizerNode{Name: "a"}))
+	is.Equal(1.5, n.Normalize(1500*time.Millisecond))
+
+	is.Equal(
+		map[string]any{
+			"node":  "node:b",
+			"int":   int64(1),
+			"dur":   float64(1),
+			"group": map[string]any{"list": []any{"node:c"}},
+		},
+		n.AttrsToMap(
+			slog.Any("node", &testNormalizerNode{Name: "b"}),
+			slog.Int("int", 1),
+			slog.Duration("dur", time.Second),
+			slog.Group("group", slog.Any("list", []*testNormalizerNode{{Name: "c"}})),
+		),
+	)
+
+	attrs := ReplaceAttrs(n.ReplaceAttr(), nil, slog.Any("node", &testNormalizerNode{Name: "d"}), slog.Int("int", 2))
+	is.Equal([]slog.Attr{slog.Any("node", "node:d"), slog.Int("int", 2)}, attrs)
+}
@@ -0,0 +1,271 @@
+package slogcommon
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// OTelSpanContext carries the trace identifiers attached to a log record.
+type OTelSpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+}
+
+type OTelOptions struct {
+	// SpanContext extracts the active span from the context (optional).
+	SpanContext func(ctx context.Context) (OTelSpanContext, bool)
+	// ObservedTime defaults to time.Now.
+	ObservedTime func() time.Time
+}
+
+// OTelLogRecord follows the OTLP/JSON encoding of the OpenTelemetry LogRecord.
+type OTelLogRecord struct {
+	TimeUnixNano         uint64         `json:"timeUnixNano,string,omitempty"`
+	ObservedTimeUnixNano uint64         `json:"observedTimeUnixNano,string,omitempty"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 *OTelAnyValue  `json:"body,omitempty"`
+	Attributes           []OTelKeyValue `json:"attributes,omitempty"`
+	Flags                uint32         `json:"flags,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type OTelKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTelAnyValue `json:"value"`
+}
+
+type OTelAnyValue struct {
+	StringValue *string           `json:"stringValue,omitempty"`
+	BoolValue   *bool             `json:"boolValue,omitempty"`
+	IntValue    *int64            `json:"intValue,string,omitempty"`
+	DoubleValue *float64          `json:"doubleValue,omitempty"`
+	ArrayValue  *OTelArrayValue   `json:"arrayValue,omitempty"`
+	KvlistValue *OTelKeyValueList `json:"kvlistValue,omitempty"`
+	BytesValue  []byte            `json:"bytesValue,omitempty"`
+}
+
+type OTelArrayValue struct {
+	Values []OTelAnyValue `json:"values"`
+}
+
+type OTelKeyValueList struct {
+	Values []OTelKeyValue `json:"values"`
+}
+
+// OTelSeverity maps a slog.Level to the OpenTelemetry severity number with
+// OTelLevels, and to the level name in DefaultLevelRegistry.
+func OTelSeverity(level slog.Level) (int, string) {
+	return OTelLevels.Map(level), DefaultLevelRegistry.Name(level)
+}
+
+// RecordToOTelLogRecord converts a record to the OpenTelemetry data model. attrs
+// is the full attribute set, usually built with AppendRecordAttrsToAttrs.
+func RecordToOTelLogRecord(ctx context.Context, record *slog.Record, attrs []slog.Attr, opts OTelOptions) OTelLogRecord {
+	observed := time.Now()
+	if opts.ObservedTime != nil {
+		observed = opts.ObservedTime()
+	}
+
+	number, text := OTelSeverity(record.Level)
+	output := OTelLogRecord{
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+		SeverityNumber:       number,
+		SeverityText:         text,
+		Body:                 otelString(record.Message),
+		Attributes:           AttrsToOTelKeyValues(attrs...),
+	}
+
+	if !record.Time.IsZero() {
+		output.TimeUnixNano = uint64(record.Time.UnixNano())
+	}
+
+	if opts.SpanContext != nil && ctx != nil {
+		if sc, ok := opts.SpanContext(ctx); ok {
+			if sc.TraceID != [16]byte{} {
+				output.TraceID = hex.EncodeToString(sc.TraceID[:])
+			}
+			if sc.SpanID != [8]byte{} {
+				output.SpanID = hex.EncodeToString(sc.SpanID[:])
+			}
+			output.Flags = uint32(sc.TraceFlags)
+		}
+	}
+
+	return output
+}
+
+// AttrsToOTelKeyValues converts attributes with the same merging semantics as AttrsToMap.
+func AttrsToOTelKeyValues(attrs ...slog.Attr) []OTelKeyValue {
+	merged := mergeAttrsByKey(attrs)
+	output := make([]OTelKeyValue, 0, len(merged))
+
+	for _, attr := range merged {
+		output = append(output, OTelKeyValue{Key: attr.Key, Value: ValueToOTelAnyValue(attr.Value)})
+	}
+
+	return output
+}
+
+func ValueToOTelAnyValue(v slog.Value) OTelAnyValue {
+	v = v.Resolve()
+
+	switch v.Kind() {
+	case slog.KindGroup:
+		return OTelAnyValue{KvlistValue: &OTelKeyValueList{Values: AttrsToOTelKeyValues(v.Group()...)}}
+	case slog.KindInt64:
+		return otelInt(v.Int64())
+	case slog.KindUint64:
+		if u := v.Uint64(); u <= math.MaxInt64 {
+			return otelInt(int64(u))
+		}
+		return *otelString(strconv.FormatUint(v.Uint64(), 10))
+	case slog.KindFloat64:
+		f := v.Float64()
+		return OTelAnyValue{DoubleValue: &f}
+	case slog.KindString:
+		return *otelString(v.String())
+	case slog.KindBool:
+		b := v.Bool()
+		return OTelAnyValue{BoolValue: &b}
+	case slog.KindDuration:
+		return otelInt(int64(v.Duration()))
+	case slog.KindTime:
+		return otelInt(v.Time().UnixNano())
+	default:
+		return anyToOTelAnyValue(v.Any())
+	}
+}
+
+func anyToOTelAnyValue(value any) OTelAnyValue {
+	switch x := value.(type) {
+	case nil:
+		return OTelAnyValue{}
+	case error:
+		return *otelString(x.Error())
+	case []byte:
+		return OTelAnyValue{BytesValue: x}
+	case slog.Value:
+		return ValueToOTelAnyValue(x)
+	case []slog.Attr:
+		return OTelAnyValue{KvlistValue: &OTelKeyValueList{Values: AttrsToOTelKeyValues(x...)}}
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := make([]OTelAnyValue, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			values = append(values, ValueToOTelAnyValue(slog.AnyValue(rv.Index(i).Interface())))
+		}
+		return OTelAnyValue{ArrayValue: &OTelArrayValue{Values: values}}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		attrs := make([]slog.Attr, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			attrs = append(attrs, slog.Any(iter.Key().String(), iter.Value().Interface()))
+		}
+		sortAttrsByKey(attrs)
+		return OTelAnyValue{KvlistValue: &OTelKeyValueList{Values: AttrsToOTelKeyValues(attrs...)}}
+	}
+
+	return *otelString(AnyValueToString(slog.AnyValue(value)))
+}
+
+func otelString(s string) *OTelAnyValue {
+	return &OTelAnyValue{StringValue: &s}
+}
+
+func otelInt(i int64) OTelAnyValue {
+	return OTelAnyValue{IntValue: &i}
+}
+
+type OTLPLogsExportRequest struct {
+	ResourceLogs []OTLPResourceLogs `json:"resourceLogs"`
+}
+
+type OTLPResourceLogs struct {
+	Resource  OTLPResource    `json:"resource"`
+	ScopeLogs []OTLPScopeLogs `json:"scopeLogs"`
+}
+
+type OTLPResource struct {
+	Attributes []OTelKeyValue `json:"attributes,omitempty"`
+}
+
+type OTLPScopeLogs struct {
+	Scope      OTLPScope       `json:"scope"`
+	LogRecords []OTelLogRecord `json:"logRecords"`
+}
+
+type OTLPScope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+func NewOTLPLogsExportRequest(resource []slog.Attr, scope OTLPScope, records ...OTelLogRecord) OTLPLogsExportRequest {
+	return OTLPLogsExportRequest{
+		ResourceLogs: []OTLPResourceLogs{
+			{
+				Resource: OTLPResource{Attributes: AttrsToOTelKeyValues(resource...)},
+				ScopeLogs: []OTLPScopeLogs{
+					{
+						Scope:      scope,
+						LogRecords: records,
+					},
+				},
+			},
+		},
+	}
+}
+
+func EncodeOTLPJSON(w io.Writer, req OTLPLogsExportRequest) error {
+	return json.NewEncoder(w).Encode(req)
+}
+
+// PostOTLPJSON sends the request to an OTLP/HTTP endpoint, such as
+// "http://localhost:4318/v1/logs".
+func PostOTLPJSON(ctx context.Context, client *http.Client, endpoint string, req OTLPLogsExportRequest) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var body bytes.Buffer
+	if err := EncodeOTLPJSON(&body, req); err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("otlp: unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package slogcommon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTelSeverity(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	n, text := OTelSeverity(slog.LevelDebug)
+	is.Equal(5, n)
+	is.Equal("DEBUG", text)
+
+	n, text = OTelSeverity(slog.LevelInfo)
+	is.Equal(9, n)
+	is.Equal("INFO", text)
+
+	n, _ = OTelSeverity(slog.LevelWarn)
+	is.Equal(13, n)
+
+	n, text = OTelSeverity(slog.LevelError + 2)
+	is.Equal(19, n)
+	is.Equal("ERROR+2", text)
+
+	n, text = OTelSeverity(LevelFatal)
+	is.Equal(21, n)
+	is.Equal("FATAL", text)
+
+	n, _ = OTelSeverity(slog.Level(-100))
+	is.Equal(1, n)
+	n, _ = OTelSeverity(slog.Level(100))
+	is.Equal(24, n)
+}
+
+func TestRecordToOTelLogRecord(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	record := slog.NewRecord(now, slog.LevelWarn, "hello", 0)
+	attrs := []slog.Attr{
+		slog.String("str", "foo"),
+		slog.Group("user", slog.Int("id", 42)),
+		slog.Group("user", slog.Bool("admin", true)),
+		slog.Any("tags", []string{"a", "b"}),
+		slog.Any("err", assert.AnError),
+		slog.Float64("ratio", 0.5),
+	}
+
+	opts := OTelOptions{
+		ObservedTime: func() time.Time { return now },
+		SpanContext: func(ctx context.Context) (OTelSpanContext, bool) {
+			return OTelSpanContext{
+				TraceID:    [16]byte{0x01, 0x02, 15: 0xff},
+				SpanID:     [8]byte{0xaa, 7: 0xbb},
+				TraceFlags: 1,
+			}, true
+		},
+	}
+
+	output := RecordToOTelLogRecord(context.Background(), &record, attrs, opts)
+	is.Equal(uint64(now.UnixNano()), output.TimeUnixNano)
+	is.Equal(uint64(now.UnixNano()), output.ObservedTimeUnixNano)
+	is.Equal(13, output.SeverityNumber)
+	is.Equal("WARN", output.SeverityText)
+	is.Equal("hello", *output.Body.StringValue)
+	is.Equal("010200000000000000000000000000ff", output.TraceID)
+	is.Equal("aa000000000000bb", output.SpanID)
+	is.Equal(uint32(1), output.Flags)
+
+	is.Len(output.Attributes, 5)
+	is.Equal("str", output.Attributes[0].Key)
+	is.Equal("foo", *output.Attributes[0].Value.StringValue)
+	is.Equal("user", output.Attributes[1].Key)
+	is.Len(output.Attributes[1].Value.KvlistValue.Values, 2)
+	is.Equal(int64(42), *output.Attributes[1].Value.KvlistValue.Values[0].Value.IntValue)
+	is.True(*output.Attributes[1].Value.KvlistValue.Values[1].Value.BoolValue)
+	is.Len(output.Attributes[2].Value.ArrayValue.Values, 2)
+	is.Equal("b", *output.Attributes[2].Value.ArrayValue.Values[1].StringValue)
+	is.Equal(assert.AnError.Error(), *output.Attributes[3].Value.StringValue)
+	is.Equal(0.5, *output.Attributes[4].Value.DoubleValue)
+
+	// no span
+	output = RecordToOTelLogRecord(context.Background(), &record, nil, OTelOptions{})
+	is.Empty(output.TraceID)
+	is.Empty(output.SpanID)
+	is.Empty(output.Attributes)
+}
+
+func TestValueToOTelAnyValue(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal(int64(1000), *ValueToOTelAnyValue(slog.DurationValue(time.Microsecond)).IntValue)
+	is.Equal(int64(42), *ValueToOTelAnyValue(slog.Uint64Value(42)).IntValue)
+	is.Equal("18446744073709551615", *ValueToOTelAnyValue(slog.Uint64Value(^uint64(0))).StringValue)
+	is.Equal([]byte("raw"), ValueToOTelAnyValue(slog.AnyValue([]byte("raw"))).BytesValue)
+	is.Equal(OTelAnyValue{}, ValueToOTelAnyValue(slog.AnyValue(nil)))
+
+	kv := ValueToOTelAnyValue(slog.AnyValue(map[string]any{"b": 2, "a": "1"})).KvlistValue
+	is.Len(kv.Values, 2)
+	is.Equal("a", kv.Values[0].Key)
+	is.Equal("b", kv.Values[1].Key)
+
+	is.Equal("{Name:user ID:42}", *ValueToOTelAnyValue(slog.AnyValue(struct {
+		Name string
+		ID   int
+	}{"user", 42})).StringValue)
+}
+
+func TestPostOTLPJSON(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		is.Equal("/v1/logs", r.URL.Path)
+		is.Equal("application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		is.NoError(json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1, 0)
+	record := slog.NewRecord(now, slog.LevelInfo, "hello", 0)
+	logRecord := RecordToOTelLogRecord(context.Background(), &record, []slog.Attr{slog.Int("count", 3)}, OTelOptions{ObservedTime: func() time.Time { return now }})
+	req := NewOTLPLogsExportRequest([]slog.Attr{slog.String("service.name", "api")}, OTLPScope{Name: "slog-common"}, logRecord)
+
+	err := PostOTLPJSON(context.Background(), server.Client(), server.URL+"/v1/logs", req)
+	is.NoError(err)
+
+	is.Equal(
+		map[string]any{
+			"resourceLogs": []any{
+				map[string]any{
+					"resource": map[string]any{
+						"attributes": []any{
+							map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "api"}},
+						},
+					},
+					"scopeLogs": []any{
+						map[string]any{
+							"scope": map[string]any{"name": "slog-common"},
+							"logRecords": []any{
+								map[string]any{
+									"timeUnixNano":         "1000000000",
+									"observedTimeUnixNano": "1000000000",
+									"severityNumber":       float64(9),
+									"severityText":         "INFO",
+									"body":                 map[string]any{"stringValue": "hello"},
+									"attributes": []any{
+										map[string]any{"key": "count", "value": map[string]any{"intValue": "3"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		received,
+	)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	err = PostOTLPJSON(context.Background(), failing.Client(), failing.URL, req)
+	is.EqualError(err, "otlp: unexpected status code 400")
+}
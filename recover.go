@@ -0,0 +1,200 @@
+package slogcommon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+type RecoverOptions struct {
+	// Level defaults to slog.LevelError.
+	Level slog.Leveler
+	// Message defaults to "panic recovered".
+	Message string
+	// ErrorKey defaults to "error".
+	ErrorKey string
+	// StackKey defaults to "stack".
+	StackKey string
+	// RequestKey defaults to "request".
+	RequestKey string
+	// Request is logged with FormatRequest when set. RecoverMiddleware sets it.
+	Request       *http.Request
+	IgnoreHeaders bool
+	// Repanic panics again once logged. Otherwise, RecoverAndLog swallows the
+	// panic and RecoverMiddleware responds with a 500 status code.
+	Repanic bool
+}
+
+// RecoverAndLog logs panics. It must be deferred directly:
+//
+//	defer slogcommon.RecoverAndLog(logger, slogcommon.RecoverOptions{})
+func RecoverAndLog(logger *slog.Logger, opts RecoverOptions) {
+	if v := recover(); v != nil {
+		logPanic(logger, opts, v, debug.Stack())
+		if opts.Repanic {
+			panic(v)
+		}
+	}
+}
+
+// RecoverMiddleware is a net/http middleware logging panics with the request.
+// http.ErrAbortHandler is propagated without being logged.
+func RecoverMiddleware(logger *slog.Logger, opts RecoverOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+
+				opts := opts
+				opts.Request = r
+				logPanic(logger, opts, v, debug.Stack())
+
+				if opts.Repanic {
+					panic(v)
+				}
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func logPanic(logger *slog.Logger, opts RecoverOptions, v any, stack []byte) {
+	level := slog.Leveler(slog.LevelError)
+	if opts.Level != nil {
+		level = opts.Level
+	}
+	message := "panic recovered"
+	if opts.Message != "" {
+		message = opts.Message
+	}
+	errorKey := "error"
+	if opts.ErrorKey != "" {
+		errorKey = opts.ErrorKey
+	}
+	stackKey := "stack"
+	if opts.StackKey != "" {
+		stackKey = opts.StackKey
+	}
+	requestKey := "request"
+	if opts.RequestKey != "" {
+		requestKey = opts.RequestKey
+	}
+
+	err, ok := v.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", v)
+	}
+
+	frames := ParsePanicStack(stack)
+
+	attrs := []slog.Attr{}
+	if formatted, ok := FormatError(err).(map[string]any); ok {
+		// report the type of the panic value, not the one of the wrapping error
+		formatted["kind"] = reflect.TypeOf(v).String()
+		delete(formatted, "stack")
+		formatted[stackKey] = frames
+		attrs = append(attrs, slog.Any(errorKey, formatted))
+	} else {
+		attrs = append(attrs, slog.Any(errorKey, err), slog.Any(stackKey, frames))
+	}
+
+	ctx := context.Background()
+	if opts.Request != nil {
+		ctx = opts.Request.Context()
+		attrs = append(attrs, slog.Any(requestKey, FormatRequest(opts.Request, opts.IgnoreHeaders)))
+	}
+
+	logger.LogAttrs(ctx, level.Level(), message, attrs...)
+}
+
+// ParseGoroutineStack parses the output of runtime/debug.Stack, the first
+// goroutine only. Function arguments and PC offsets are dropped.
+func ParseGoroutineStack(stack []byte) []slog.Source {
+	frames := []slog.Source{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(stack))
+	var function string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			if len(frames) > 0 {
+				return frames
+			}
+		case strings.HasPrefix(line, "\t"):
+			if function == "" {
+				continue
+			}
+			frames = append(frames, parseGoroutineStackLocation(function, line))
+			function = ""
+		case line == "":
+			continue
+		default:
+			function = parseGoroutineStackFunction(line)
+		}
+	}
+
+	return frames
+}
+
+// ParsePanicStack is ParseGoroutineStack, without the frames above the call to panic.
+func ParsePanicStack(stack []byte) []slog.Source {
+	frames := ParseGoroutineStack(stack)
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].Function == "panic" {
+			return frames[i+1:]
+		}
+	}
+
+	return frames
+}
+
+// parseGoroutineStackFunction handles "pkg.(*T).Func(0x1, ...)" and
+// "created by pkg.Func in goroutine 1".
+func parseGoroutineStackFunction(line string) string {
+	if rest, ok := strings.CutPrefix(line, "created by "); ok {
+		function, _, _ := strings.Cut(rest, " in goroutine ")
+		return function
+	}
+
+	if i := strings.LastIndexByte(line, '('); i > 0 && strings.HasSuffix(line, ")") {
+		return line[:i]
+	}
+
+	return line
+}
+
+// parseGoroutineStackLocation handles "\t/path/file.go:42 +0x1d".
+func parseGoroutineStackLocation(function string, line string) slog.Source {
+	location := strings.TrimPrefix(line, "\t")
+	if i := strings.LastIndex(location, " +0x"); i >= 0 {
+		location = location[:i]
+	}
+
+	frame := slog.Source{Function: function, File: location}
+	if i := strings.LastIndexByte(location, ':'); i >= 0 {
+		if n, err := strconv.Atoi(location[i+1:]); err == nil {
+			frame.File = location[:i]
+			frame.Line = n
+		}
+	}
+
+	return frame
+}
@@ -0,0 +1,153 @@
+package slogcommon
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPanicking(logger *slog.Logger, opts RecoverOptions, v any) {
+	defer RecoverAndLog(logger, opts)
+	panic(v)
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	is.NotPanics(func() {
+		testPanicking(logger, RecoverOptions{}, "boom")
+	})
+
+	var output map[string]any
+	is.NoError(json.Unmarshal(buf.Bytes(), &output))
+	is.Equal("ERROR", output["level"])
+	is.Equal("panic recovered", output["msg"])
+
+	err := output["error"].(map[string]any)
+	is.Equal("string", err["kind"])
+	is.Equal("panic: boom", err["error"])
+
+	stack := err["stack"].([]any)
+	is.NotEmpty(stack)
+	is.Equal("github.com/samber/slog-common.testPanicking", stack[0].(map[string]any)["function"])
+	is.Contains(stack[0].(map[string]any)["file"], "recover_test.go")
+
+	// re-panic
+	buf.Reset()
+	is.PanicsWithError(assert.AnError.Error(), func() {
+		testPanicking(logger, RecoverOptions{Repanic: true, Level: slog.LevelWarn, Message: "oops", ErrorKey: "err"}, assert.AnError)
+	})
+	output = map[string]any{}
+	is.NoError(json.Unmarshal(buf.Bytes(), &output))
+	is.Equal("WARN", output["level"])
+	is.Equal("oops", output["msg"])
+	is.Equal(assert.AnError.Error(), output["err"].(map[string]any)["error"])
+	is.Equal("*errors.errorString", output["err"].(map[string]any)["kind"])
+
+	// stack key
+	buf.Reset()
+	testPanicking(logger, RecoverOptions{StackKey: "frames"}, 42)
+	output = map[string]any{}
+	is.NoError(json.Unmarshal(buf.Bytes(), &output))
+	err = output["error"].(map[string]any)
+	is.Equal("int", err["kind"])
+	is.NotContains(err, "stack")
+	is.NotEmpty(err["frames"])
+
+	// no panic
+	buf.Reset()
+	func() {
+		defer RecoverAndLog(logger, RecoverOptions{})
+	}()
+	is.Empty(buf.String())
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	handler := RecoverMiddleware(logger, RecoverOptions{IgnoreHeaders: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path?a=b", nil))
+	is.Equal(http.StatusInternalServerError, rec.Code)
+
+	var output map[string]any
+	is.NoError(json.Unmarshal(buf.Bytes(), &output))
+	is.Equal("panic: boom", output["error"].(map[string]any)["error"])
+	request := output["request"].(map[string]any)
+	is.Equal("GET", request["method"])
+	is.Equal("/path", request["url"].(map[string]any)["path"])
+	is.NotContains(request, "headers")
+
+	// abort handler
+	buf.Reset()
+	handler = RecoverMiddleware(logger, RecoverOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	is.PanicsWithError(http.ErrAbortHandler.Error(), func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	is.Empty(buf.String())
+
+	// re-panic
+	handler = RecoverMiddleware(logger, RecoverOptions{Repanic: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	is.PanicsWithValue("boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	is.NotEmpty(buf.String())
+}
+
+func TestParseGoroutineStack(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	stack := []byte(`goroutine 1 [running]:
+runtime/debug.Stack()
+	/usr/local/go/src/runtime/debug/stack.go:26 +0x5e
+panic({0x4a1b20?, 0x4f0b40?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+main.(*T).handle(0xc000012345, {0x0, 0x0})
+	/app/main.go:12 +0x1d
+main.main()
+	/app/main.go:20
+created by main.start in goroutine 1
+	/app/start.go:5 +0x25
+
+goroutine 2 [chan receive]:
+main.other()
+	/app/other.go:1 +0x1
+`)
+
+	is.Equal([]slog.Source{
+		{Function: "runtime/debug.Stack", File: "/usr/local/go/src/runtime/debug/stack.go", Line: 26},
+		{Function: "panic", File: "/usr/local/go/src/runtime/panic.go", Line: 770},
+		{Function: "main.(*T).handle", File: "/app/main.go", Line: 12},
+		{Function: "main.main", File: "/app/main.go", Line: 20},
+		{Function: "main.start", File: "/app/start.go", Line: 5},
+	}, ParseGoroutineStack(stack))
+
+	is.Equal([]slog.Source{
+		{Function: "main.(*T).handle", File: "/app/main.go", Line: 12},
+		{Function: "main.main", File: "/app/main.go", Line: 20},
+		{Function: "main.start", File: "/app/start.go", Line: 5},
+	}, ParsePanicStack(stack))
+
+	is.Equal([]slog.Source{}, ParseGoroutineStack(nil))
+}
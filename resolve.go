@@ -0,0 +1,144 @@
+package slogcommon
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+const (
+	safeResolveDefaultMaxDepth = 32
+	// same bound as slog.Value.Resolve
+	safeResolveMaxLogValuerCalls = 100
+)
+
+var (
+	ErrResolveMaxDepth = errors.New("slog: max depth exceeded")
+	ErrResolveCycle    = errors.New("slog: LogValuer cycle detected")
+)
+
+type SafeResolveOptions struct {
+	// MaxDepth bounds group nesting. Defaults to 32.
+	MaxDepth int
+	// Marker replaces truncated values. Defaults to a string describing the reason.
+	Marker *slog.Value
+	// OnTruncate is called for each truncated attribute (optional).
+	OnTruncate func(groups []string, key string, reason error)
+}
+
+// SafeResolveAttrs resolves LogValuers recursively, like ReplaceAttrs does, but
+// stops at MaxDepth and on LogValuer cycles, replacing the offending value by a
+// marker. The returned boolean reports whether anything was truncated.
+func SafeResolveAttrs(opts SafeResolveOptions, attrs ...slog.Attr) ([]slog.Attr, bool) {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = safeResolveDefaultMaxDepth
+	}
+
+	r := safeResolver{opts: opts, visiting: map[uintptr]struct{}{}}
+	output := r.attrs(nil, attrs)
+	return output, r.truncated
+}
+
+// SafeReplaceAttrs is a bounded variant of ReplaceAttrs.
+func SafeReplaceAttrs(opts SafeResolveOptions, fn ReplaceAttrFn, groups []string, attrs ...slog.Attr) []slog.Attr {
+	resolved, _ := SafeResolveAttrs(opts, attrs...)
+	return ReplaceAttrs(fn, groups, resolved...)
+}
+
+// SafeAttrsToMap is a bounded variant of AttrsToMap.
+func SafeAttrsToMap(opts SafeResolveOptions, attrs ...slog.Attr) map[string]any {
+	resolved, _ := SafeResolveAttrs(opts, attrs...)
+	return AttrsToMap(resolved...)
+}
+
+type safeResolver struct {
+	opts      SafeResolveOptions
+	visiting  map[uintptr]struct{}
+	truncated bool
+}
+
+func (r *safeResolver) attrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	output := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		output = append(output, slog.Attr{Key: attr.Key, Value: r.value(groups, attr.Key, attr.Value)})
+	}
+
+	return output
+}
+
+func (r *safeResolver) value(groups []string, key string, v slog.Value) slog.Value {
+	var entered []uintptr
+	defer func() {
+		for _, ptr := range entered {
+			delete(r.visiting, ptr)
+		}
+	}()
+
+	for i := 0; v.Kind() == slog.KindLogValuer; i++ {
+		if i >= safeResolveMaxLogValuerCalls {
+			return r.truncate(groups, key, ErrResolveMaxDepth)
+		}
+
+		lv := v.LogValuer()
+		if ptr, ok := logValuerIdentity(lv); ok {
+			if _, ok := r.visiting[ptr]; ok {
+				return r.truncate(groups, key, ErrResolveCycle)
+			}
+			r.visiting[ptr] = struct{}{}
+			entered = append(entered, ptr)
+		}
+
+		v = safeLogValue(lv)
+	}
+
+	if v.Kind() != slog.KindGroup {
+		return v
+	}
+
+	if len(groups) >= r.opts.MaxDepth {
+		return r.truncate(groups, key, ErrResolveMaxDepth)
+	}
+
+	return slog.GroupValue(r.attrs(append(groups, key), v.Group())...)
+}
+
+func (r *safeResolver) truncate(groups []string, key string, reason error) slog.Value {
+	r.truncated = true
+
+	if r.opts.OnTruncate != nil {
+		r.opts.OnTruncate(append([]string{}, groups...), key, reason)
+	}
+
+	if r.opts.Marker != nil {
+		return *r.opts.Marker
+	}
+
+	return slog.StringValue("!" + reason.Error())
+}
+
+// logValuerIdentity returns the address behind reference-like LogValuers, which
+// are the only ones able to form a cycle.
+func logValuerIdentity(lv slog.LogValuer) (uintptr, bool) {
+	rv := reflect.ValueOf(lv)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if rv.IsNil() {
+			return 0, false
+		}
+		return rv.Pointer(), true
+	default:
+		return 0, false
+	}
+}
+
+// safeLogValue mirrors slog.Value.Resolve, turning panics into error values.
+func safeLogValue(lv slog.LogValuer) (v slog.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			v = slog.AnyValue(fmt.Errorf("LogValue panicked: %v", r))
+		}
+	}()
+
+	return lv.LogValue()
+}
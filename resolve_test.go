@@ -0,0 +1,103 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCyclicLogValuer struct {
+	name string
+	next *testCyclicLogValuer
+}
+
+func (v *testCyclicLogValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", v.name), slog.Any("next", v.next))
+}
+
+type testRecursiveLogValuer struct{}
+
+func (testRecursiveLogValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("child", testRecursiveLogValuer{}))
+}
+
+type testPanickingLogValuer struct{}
+
+func (testPanickingLogValuer) LogValue() slog.Value {
+	panic("boom")
+}
+
+func TestSafeResolveAttrs(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	// no truncation
+	attrs, truncated := SafeResolveAttrs(SafeResolveOptions{}, slog.Any("user", stubLogValuer), slog.Int("int", 1))
+	is.False(truncated)
+	is.Equal([]slog.Attr{
+		slog.Group("user", slog.String("name", "userName"), slog.String("password", "********")),
+		slog.Int("int", 1),
+	}, attrs)
+
+	// cycle
+	a := &testCyclicLogValuer{name: "a"}
+	b := &testCyclicLogValuer{name: "b", next: a}
+	a.next = b
+
+	var reports []string
+	attrs, truncated = SafeResolveAttrs(SafeResolveOptions{
+		OnTruncate: func(groups []string, key string, reason error) {
+			is.ErrorIs(reason, ErrResolveCycle)
+			is.Equal([]string{"a", "next"}, groups)
+			reports = append(reports, key)
+		},
+	}, slog.Any("a", a))
+	is.True(truncated)
+	is.Equal([]string{"next"}, reports)
+	is.Equal([]slog.Attr{
+		slog.Group("a",
+			slog.String("name", "a"),
+			slog.Group("next",
+				slog.String("name", "b"),
+				slog.String("next", "!slog: LogValuer cycle detected"),
+			),
+		),
+	}, attrs)
+
+	// the same pointer twice, side by side, is not a cycle
+	c := &testCyclicLogValuer{name: "c"}
+	_, truncated = SafeResolveAttrs(SafeResolveOptions{}, slog.Any("x", c), slog.Any("y", c))
+	is.False(truncated)
+
+	// infinite depth
+	marker := slog.StringValue("…")
+	attrs, truncated = SafeResolveAttrs(SafeResolveOptions{MaxDepth: 2, Marker: &marker}, slog.Any("r", testRecursiveLogValuer{}))
+	is.True(truncated)
+	is.Equal([]slog.Attr{
+		slog.Group("r", slog.Group("child", slog.String("child", "…"))),
+	}, attrs)
+
+	// panics
+	attrs, truncated = SafeResolveAttrs(SafeResolveOptions{}, slog.Any("p", testPanickingLogValuer{}))
+	is.False(truncated)
+	is.EqualError(attrs[0].Value.Any().(error), "LogValue panicked: boom")
+}
+
+func TestSafeAttrsToMap(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	a := &testCyclicLogValuer{name: "a"}
+	a.next = a
+
+	is.Equal(
+		map[string]any{"a": map[string]any{"name": "a", "next": "!slog: LogValuer cycle detected"}},
+		SafeAttrsToMap(SafeResolveOptions{}, slog.Any("a", a)),
+	)
+
+	attrs := SafeReplaceAttrs(SafeResolveOptions{MaxDepth: 1}, func(groups []string, attr slog.Attr) slog.Attr {
+		return attr
+	}, nil, slog.Any("r", testRecursiveLogValuer{}))
+	is.Equal([]slog.Attr{slog.Group("r", slog.String("child", "!slog: max depth exceeded"))}, attrs)
+}
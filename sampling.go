@@ -0,0 +1,252 @@
+package slogcommon
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	thresholdSamplerBuckets     = 4096
+	thresholdSamplerDefaultTick = time.Second
+	runEveryDefaultInterval     = time.Second
+)
+
+// Sampler decides whether a record is kept. attrs is the full attribute set,
+// usually built with AppendRecordAttrsToAttrs.
+type Sampler interface {
+	Sample(record *slog.Record, attrs []slog.Attr) bool
+}
+
+type SamplerFunc func(record *slog.Record, attrs []slog.Attr) bool
+
+func (f SamplerFunc) Sample(record *slog.Record, attrs []slog.Attr) bool {
+	return f(record, attrs)
+}
+
+// NewUniformSampler keeps records with probability rate, in [0, 1]. random
+// defaults to math/rand.Float64.
+func NewUniformSampler(rate float64, random func() float64) Sampler {
+	if random == nil {
+		random = rand.Float64
+	}
+
+	return SamplerFunc(func(record *slog.Record, attrs []slog.Attr) bool {
+		return random() < rate
+	})
+}
+
+type ThresholdSamplerOptions struct {
+	// Tick is the counting interval. Defaults to 1s.
+	Tick time.Duration
+	// First records of each key are kept during a tick.
+	First uint64
+	// Thereafter keeps every Thereafter-th record once First is reached. Zero drops
+	// them all.
+	Thereafter uint64
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewThresholdSampler keeps the first N records per interval, then every Mth,
+// keyed by level and message. Keys are hashed into a fixed number of buckets,
+// so memory is bounded and rare collisions share a counter.
+func NewThresholdSampler(opts ThresholdSamplerOptions) Sampler {
+	if opts.Tick <= 0 {
+		opts.Tick = thresholdSamplerDefaultTick
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	s := &thresholdSampler{opts: opts}
+	return SamplerFunc(s.sample)
+}
+
+type thresholdSampler struct {
+	opts    ThresholdSamplerOptions
+	mu      sync.Mutex
+	buckets [thresholdSamplerBuckets]thresholdSamplerCounter
+}
+
+type thresholdSamplerCounter struct {
+	resetAt time.Time
+	n       uint64
+}
+
+func (s *thresholdSampler) sample(record *slog.Record, attrs []slog.Attr) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(record.Level.String()))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(record.Message))
+
+	now := s.opts.Now()
+
+	s.mu.Lock()
+	counter := &s.buckets[h.Sum32()%thresholdSamplerBuckets]
+	if !now.Before(counter.resetAt) {
+		counter.resetAt = now.Add(s.opts.Tick)
+		counter.n = 0
+	}
+	counter.n++
+	n := counter.n
+	s.mu.Unlock()
+
+	if n <= s.opts.First {
+		return true
+	}
+
+	return s.opts.Thereafter > 0 && (n-s.opts.First)%s.opts.Thereafter == 0
+}
+
+type TraceSamplerOptions struct {
+	// Groups and Key locate the trace id. Key defaults to "trace_id".
+	Groups []string
+	Key    string
+	// Rate is the share of traces kept, in [0, 1].
+	Rate float64
+	// Fallback samples records without trace id. Defaults to keeping them.
+	Fallback Sampler
+}
+
+// NewTraceSampler hashes the trace id, so that all records of a trace are kept or
+// dropped together, across processes.
+func NewTraceSampler(opts TraceSamplerOptions) Sampler {
+	if opts.Key == "" {
+		opts.Key = "trace_id"
+	}
+
+	return SamplerFunc(func(record *slog.Record, attrs []slog.Attr) bool {
+		attr, ok := FindAttribute(attrs, opts.Groups, opts.Key)
+		if !ok {
+			if opts.Fallback == nil {
+				return true
+			}
+			return opts.Fallback.Sample(record, attrs)
+		}
+
+		if opts.Rate >= 1 {
+			return true
+		}
+
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(ValueToString(attr.Value)))
+		return float64(fmix64(h.Sum64())) < opts.Rate*math.MaxUint64
+	})
+}
+
+// fmix64 is the MurmurHash3 finalizer. FNV alone barely changes the high bits
+// for ids sharing a prefix.
+func fmix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// CountingSampler counts records dropped by a sampler, per level.
+type CountingSampler struct {
+	sampler Sampler
+
+	mu      sync.Mutex
+	dropped map[slog.Level]uint64
+	total   atomic.Uint64
+}
+
+func NewCountingSampler(sampler Sampler) *CountingSampler {
+	return &CountingSampler{
+		sampler: sampler,
+		dropped: map[slog.Level]uint64{},
+	}
+}
+
+func (s *CountingSampler) Sample(record *slog.Record, attrs []slog.Attr) bool {
+	if s.sampler.Sample(record, attrs) {
+		return true
+	}
+
+	s.mu.Lock()
+	s.dropped[record.Level]++
+	s.mu.Unlock()
+	s.total.Add(1)
+
+	return false
+}
+
+// Dropped returns the total number of dropped records.
+func (s *CountingSampler) Dropped() uint64 {
+	return s.total.Load()
+}
+
+// Flush returns and resets the dropped records counters.
+func (s *CountingSampler) Flush() map[slog.Level]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := s.dropped
+	s.dropped = map[slog.Level]uint64{}
+	return dropped
+}
+
+// Report calls fn with the flushed counters every interval, when records were
+// dropped. A non-positive interval defaults to 1s. The returned function stops
+// reporting.
+func (s *CountingSampler) Report(interval time.Duration, fn func(dropped map[slog.Level]uint64)) (stop func()) {
+	return runEvery(interval, func() {
+		if dropped := s.Flush(); len(dropped) > 0 {
+			fn(dropped)
+		}
+	})
+}
+
+// runEvery calls fn every interval (1s when not positive) from a goroutine. The
+// returned function stops the goroutine and waits for a running call to return.
+// It is idempotent.
+func runEvery(interval time.Duration, fn func()) (stop func()) {
+	if interval <= 0 {
+		interval = runEveryDefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// SampledOutAttrs renders counters as attributes sorted by key, eg: INFO=12.
+func SampledOutAttrs(dropped map[slog.Level]uint64) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(dropped))
+	for level, n := range dropped {
+		attrs = append(attrs, slog.Uint64(DefaultLevelRegistry.Name(level), n))
+	}
+	sortAttrsByKey(attrs)
+
+	return attrs
+}
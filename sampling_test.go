@@ -0,0 +1,150 @@
+package slogcommon
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniformSampler(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	values := []float64{0.1, 0.5, 0.9}
+	i := 0
+	s := NewUniformSampler(0.5, func() float64 {
+		v := values[i%len(values)]
+		i++
+		return v
+	})
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	is.True(s.Sample(&record, nil))
+	is.False(s.Sample(&record, nil))
+	is.False(s.Sample(&record, nil))
+
+	is.False(NewUniformSampler(0, nil).Sample(&record, nil))
+	is.True(NewUniformSampler(1, nil).Sample(&record, nil))
+}
+
+func TestThresholdSampler(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewThresholdSampler(ThresholdSamplerOptions{
+		Tick:       time.Second,
+		First:      2,
+		Thereafter: 3,
+		Now:        func() time.Time { return now },
+	})
+
+	a := slog.NewRecord(now, slog.LevelInfo, "a", 0)
+	b := slog.NewRecord(now, slog.LevelInfo, "b", 0)
+	aWarn := slog.NewRecord(now, slog.LevelWarn, "a", 0)
+
+	kept := []bool{}
+	for i := 0; i < 8; i++ {
+		kept = append(kept, s.Sample(&a, nil))
+	}
+	is.Equal([]bool{true, true, false, false, true, false, false, true}, kept)
+	is.True(s.Sample(&b, nil))
+	is.True(s.Sample(&aWarn, nil))
+
+	// next tick
+	now = now.Add(time.Second)
+	is.True(s.Sample(&a, nil))
+	is.True(s.Sample(&a, nil))
+	is.False(s.Sample(&a, nil))
+
+	// drop all after First
+	s = NewThresholdSampler(ThresholdSamplerOptions{Tick: time.Hour, First: 1})
+	is.True(s.Sample(&a, nil))
+	is.False(s.Sample(&a, nil))
+	is.False(s.Sample(&a, nil))
+
+	// Tick defaults to 1s
+	now = time.Now()
+	s = NewThresholdSampler(ThresholdSamplerOptions{First: 1, Now: func() time.Time { return now }})
+	is.True(s.Sample(&a, nil))
+	is.False(s.Sample(&a, nil))
+	now = now.Add(time.Second)
+	is.True(s.Sample(&a, nil))
+}
+
+func TestTraceSampler(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	s := NewTraceSampler(TraceSamplerOptions{Groups: []string{"trace"}, Key: "id", Rate: 0.5, Fallback: SamplerFunc(func(*slog.Record, []slog.Attr) bool { return false })})
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		attrs := []slog.Attr{slog.Group("trace", slog.String("id", fmt.Sprintf("%032x", i)))}
+		decision := s.Sample(&record, attrs)
+		// consistent
+		is.Equal(decision, s.Sample(&record, attrs))
+		if decision {
+			kept++
+		}
+	}
+	is.InDelta(500, kept, 100)
+
+	is.False(s.Sample(&record, []slog.Attr{slog.String("id", "1")}))
+	is.True(NewTraceSampler(TraceSamplerOptions{Rate: 0}).Sample(&record, nil))
+	is.False(NewTraceSampler(TraceSamplerOptions{Rate: 0}).Sample(&record, []slog.Attr{slog.String("trace_id", "1")}))
+	is.True(NewTraceSampler(TraceSamplerOptions{Rate: 1}).Sample(&record, []slog.Attr{slog.String("trace_id", "1")}))
+}
+
+func TestCountingSampler(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	s := NewCountingSampler(NewUniformSampler(0, nil))
+	info := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	warn := slog.NewRecord(time.Now(), slog.LevelWarn, "msg", 0)
+
+	s.Sample(&info, nil)
+	s.Sample(&info, nil)
+	s.Sample(&warn, nil)
+	is.Equal(uint64(3), s.Dropped())
+
+	dropped := s.Flush()
+	is.Equal(map[slog.Level]uint64{slog.LevelInfo: 2, slog.LevelWarn: 1}, dropped)
+	is.Equal([]slog.Attr{slog.Uint64("INFO", 2), slog.Uint64("WARN", 1)}, SampledOutAttrs(dropped))
+	is.Empty(s.Flush())
+	is.Equal(uint64(3), s.Dropped())
+
+	// periodic reports
+	var mu sync.Mutex
+	reports := []map[slog.Level]uint64{}
+	stop := s.Report(5*time.Millisecond, func(dropped map[slog.Level]uint64) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, dropped)
+	})
+	defer stop()
+
+	s.Sample(&warn, nil)
+	is.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reports) == 1
+	}, time.Second, time.Millisecond)
+
+	stop()
+	stop()
+	mu.Lock()
+	is.Equal(map[slog.Level]uint64{slog.LevelWarn: 1}, reports[0])
+	mu.Unlock()
+
+	// zero interval
+	is.NotPanics(func() {
+		s.Report(0, func(map[slog.Level]uint64) {})()
+	})
+}
@@ -0,0 +1,418 @@
+package slogcommon
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sentryMaxExceptions bounds the walk of error trees.
+const sentryMaxExceptions = 32
+
+// DefaultSentrySensitiveHeaders lists the request headers left out of Sentry
+// events by default.
+var DefaultSentrySensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+type SentryOptions struct {
+	// ErrorKeys defaults to "error" and "err".
+	ErrorKeys []string
+	// RequestKey is the attribute holding a *http.Request. Defaults to "request".
+	RequestKey string
+	// SensitiveHeaders are removed from the request, case-insensitively. Defaults to
+	// DefaultSentrySensitiveHeaders.
+	SensitiveHeaders []string
+	// UserPaths maps Sentry user fields ("id", "email", "username", "ip_address")
+	// to attribute paths. Defaults to the same keys under the "user" group.
+	UserPaths map[string][]string
+	// TagKeys lists the top-level attributes sent as tags; the others go to extra.
+	TagKeys []string
+	// Fingerprint overrides the default Sentry grouping (optional).
+	Fingerprint func(record *slog.Record, attrs []slog.Attr) []string
+	// InAppPrefixes marks frames whose function starts with one of these prefixes as
+	// in_app. When empty, every frame outside the standard library is in_app.
+	InAppPrefixes []string
+
+	Logger      string
+	Environment string
+	Release     string
+	ServerName  string
+
+	// EventID defaults to a random 128-bit hex string.
+	EventID func() string
+}
+
+type SentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Exception   *SentryExceptions `json:"exception,omitempty"`
+	Request     *SentryRequest    `json:"request,omitempty"`
+	User        map[string]string `json:"user,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+}
+
+type SentryExceptions struct {
+	Values []SentryException `json:"values"`
+}
+
+type SentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *SentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type SentryStacktrace struct {
+	Frames []SentryFrame `json:"frames"`
+}
+
+type SentryFrame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+type SentryRequest struct {
+	URL         string            `json:"url,omitempty"`
+	Method      string            `json:"method,omitempty"`
+	QueryString string            `json:"query_string,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+// SentryLevel maps a slog.Level to a Sentry level, following SentryLevels.
+func SentryLevel(level slog.Level) string {
+	return SentryLevels.Map(level)
+}
+
+// RecordToSentryEvent builds a Sentry event payload. attrs is the full attribute
+// set, usually built with AppendRecordAttrsToAttrs.
+func RecordToSentryEvent(record *slog.Record, attrs []slog.Attr, opts SentryOptions) SentryEvent {
+	errorKeys := opts.ErrorKeys
+	if len(errorKeys) == 0 {
+		errorKeys = []string{"error", "err"}
+	}
+	requestKey := opts.RequestKey
+	if requestKey == "" {
+		requestKey = "request"
+	}
+
+	eventID := ""
+	if opts.EventID != nil {
+		eventID = opts.EventID()
+	} else {
+		eventID = randomID()
+	}
+
+	timestamp := record.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	event := SentryEvent{
+		EventID:     eventID,
+		Timestamp:   timestamp.UTC().Format(time.RFC3339Nano),
+		Level:       SentryLevel(record.Level),
+		Platform:    "go",
+		Logger:      opts.Logger,
+		Environment: opts.Environment,
+		Release:     opts.Release,
+		ServerName:  opts.ServerName,
+		Message:     record.Message,
+	}
+
+	if opts.Fingerprint != nil {
+		event.Fingerprint = opts.Fingerprint(record, attrs)
+	}
+
+	attrs, err := ExtractError(attrs, errorKeys...)
+	if err != nil {
+		event.Exception = &SentryExceptions{Values: SentryExceptionsFromError(err, opts.InAppPrefixes)}
+	}
+
+	if attr, ok := FindAttrByKey(attrs, requestKey); ok {
+		if req, ok := attr.Value.Resolve().Any().(*http.Request); ok && req != nil {
+			sensitive := opts.SensitiveHeaders
+			if sensitive == nil {
+				sensitive = DefaultSentrySensitiveHeaders
+			}
+			event.Request = sentryRequest(FormatRequest(req, false), sensitive)
+			attrs = removeAttrsByKey(attrs, requestKey)
+		}
+	}
+
+	event.User, attrs = sentryUser(attrs, opts.UserPaths)
+
+	var tags []slog.Attr
+	var extra []slog.Attr
+	for _, attr := range attrs {
+		if slices.Contains(opts.TagKeys, attr.Key) && attr.Value.Resolve().Kind() != slog.KindGroup {
+			tags = append(tags, attr)
+		} else {
+			extra = append(extra, attr)
+		}
+	}
+
+	if len(tags) > 0 {
+		event.Tags = AttrsToString(tags...)
+	}
+	if len(extra) > 0 {
+		event.Extra = AttrsToMap(extra...)
+	}
+
+	return event
+}
+
+// SentryExceptionsFromError unwraps the error tree, including errors.Join
+// branches, depth-first. Following the Sentry convention, the innermost cause
+// comes first and the outermost error last.
+func SentryExceptionsFromError(err error, inAppPrefixes []string) []SentryException {
+	var output []SentryException
+
+	seen := map[error]struct{}{}
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil || len(output) >= sentryMaxExceptions {
+			return
+		}
+		if isComparable(err) {
+			if _, ok := seen[err]; ok {
+				return
+			}
+			seen[err] = struct{}{}
+		}
+
+		exception := SentryException{
+			Type:  reflect.TypeOf(err).String(),
+			Value: err.Error(),
+		}
+		if pcs := errorStackPCs(err); len(pcs) > 0 {
+			exception.Stacktrace = &SentryStacktrace{Frames: SentryFrames(pcs, inAppPrefixes)}
+		}
+		output = append(output, exception)
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		}
+	}
+	walk(err)
+
+	slices.Reverse(output)
+
+	return output
+}
+
+// SentryFrames symbolizes program counters, oldest call first as Sentry expects.
+func SentryFrames(pcs []uintptr, inAppPrefixes []string) []SentryFrame {
+	var frames []SentryFrame
+
+	iter := runtime.CallersFrames(pcs)
+	for {
+		f, more := iter.Next()
+		if f.Function != "" || f.File != "" {
+			module, function := splitFunctionName(f.Function)
+			frames = append(frames, SentryFrame{
+				Function: function,
+				Module:   module,
+				Filename: baseName(f.File),
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    isInApp(f.Function, inAppPrefixes),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	slices.Reverse(frames)
+
+	return frames
+}
+
+// errorStackPCs supports the common stack carrying errors: `Callers() []uintptr`
+// (go-errors) and `StackTrace()` returning a slice of uintptr based frames (pkg/errors).
+func errorStackPCs(err error) []uintptr {
+	if e, ok := err.(interface{ Callers() []uintptr }); ok {
+		return e.Callers()
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	out := method.Call(nil)[0]
+	if out.Kind() != reflect.Slice || out.Type().Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	pcs := make([]uintptr, 0, out.Len())
+	for i := 0; i < out.Len(); i++ {
+		pcs = append(pcs, uintptr(out.Index(i).Uint()))
+	}
+
+	return pcs
+}
+
+func sentryRequest(values map[string]any, sensitiveHeaders []string) *SentryRequest {
+	req := &SentryRequest{}
+	req.Method, _ = values["method"].(string)
+
+	if u, ok := values["url"].(map[string]any); ok {
+		if scheme, _ := u["scheme"].(string); scheme != "" {
+			host, _ := u["host"].(string)
+			path, _ := u["path"].(string)
+			req.URL = scheme + "://" + host + path
+		} else {
+			host, _ := values["host"].(string)
+			path, _ := u["path"].(string)
+			req.URL = "http://" + host + path
+		}
+		req.QueryString, _ = u["raw_query"].(string)
+	}
+
+	req.Headers, _ = values["headers"].(map[string]string)
+	for key := range req.Headers {
+		if slices.ContainsFunc(sensitiveHeaders, func(h string) bool { return strings.EqualFold(h, key) }) {
+			delete(req.Headers, key)
+		}
+	}
+
+	return req
+}
+
+func sentryUser(attrs []slog.Attr, paths map[string][]string) (map[string]string, []slog.Attr) {
+	if paths == nil {
+		paths = map[string][]string{
+			"id":         {"user", "id"},
+			"email":      {"user", "email"},
+			"username":   {"user", "username"},
+			"ip_address": {"user", "ip_address"},
+		}
+	}
+
+	user := map[string]string{}
+	for _, field := range []string{"id", "email", "username", "ip_address"} {
+		path, ok := paths[field]
+		if !ok || len(path) == 0 {
+			continue
+		}
+
+		var value string
+		if value, attrs = extractAttrStringByPath(attrs, path); value != "" {
+			user[field] = value
+		}
+	}
+
+	if len(user) == 0 {
+		return nil, attrs
+	}
+
+	return user, attrs
+}
+
+// SentryEnvelope serializes an event to the envelope format accepted by the
+// Sentry ingestion endpoint (/api/<project>/envelope/).
+func SentryEnvelope(event SentryEvent, dsn string) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := json.Marshal(map[string]any{
+		"event_id": event.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      dsn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := json.Marshal(map[string]any{
+		"type":   "event",
+		"length": len(payload),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]byte, 0, len(header)+len(item)+len(payload)+3)
+	output = append(output, header...)
+	output = append(output, '\n')
+	output = append(output, item...)
+	output = append(output, '\n')
+	output = append(output, payload...)
+	output = append(output, '\n')
+
+	return output, nil
+}
+
+// splitFunctionName turns "github.com/a/b.(*T).M" into "github.com/a/b" and "(*T).M".
+func splitFunctionName(name string) (string, string) {
+	lastSlash := strings.LastIndexByte(name, '/')
+	if i := strings.IndexByte(name[lastSlash+1:], '.'); i >= 0 {
+		i += lastSlash + 1
+		return name[:i], name[i+1:]
+	}
+
+	return "", name
+}
+
+func isInApp(function string, prefixes []string) bool {
+	if len(prefixes) > 0 {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(function, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return !isStdlibFunction(function)
+}
+
+// isStdlibFunction relies on the fact that standard library import paths have no
+// dot in their first element.
+func isStdlibFunction(function string) bool {
+	module, _ := splitFunctionName(function)
+	if module == "" || module == "main" {
+		return module == ""
+	}
+
+	first, _, _ := strings.Cut(module, "/")
+	return !strings.Contains(first, ".")
+}
+
+func baseName(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		return path[i+1:]
+	}
+
+	return path
+}
+
+// isComparable checks the dynamic value: a comparable type may still hold an
+// interface field with an uncomparable value.
+func isComparable(v any) bool {
+	return reflect.ValueOf(v).Comparable()
+}
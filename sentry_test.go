@@ -0,0 +1,220 @@
+package slogcommon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newTestStackError(msg string) *testStackError {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	return &testStackError{msg: msg, pcs: pcs[:n]}
+}
+
+func (e *testStackError) Error() string      { return e.msg }
+func (e *testStackError) Callers() []uintptr { return e.pcs }
+
+// parseTestSentryEnvelope is a minimal envelope parser: a header line followed by
+// items made of a header line and a payload of the announced length.
+func parseTestSentryEnvelope(t *testing.T, data []byte) (map[string]any, []map[string]any, [][]byte) {
+	header, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		t.Fatal("missing envelope header")
+	}
+
+	var envelopeHeader map[string]any
+	if err := json.Unmarshal(header, &envelopeHeader); err != nil {
+		t.Fatal(err)
+	}
+
+	var itemHeaders []map[string]any
+	var payloads [][]byte
+	for len(rest) > 0 {
+		line, tail, _ := bytes.Cut(rest, []byte("\n"))
+
+		var itemHeader map[string]any
+		if err := json.Unmarshal(line, &itemHeader); err != nil {
+			t.Fatal(err)
+		}
+
+		length := int(itemHeader["length"].(float64))
+		if len(tail) < length {
+			t.Fatal("truncated item")
+		}
+
+		itemHeaders = append(itemHeaders, itemHeader)
+		payloads = append(payloads, tail[:length])
+		rest = bytes.TrimPrefix(tail[length:], []byte("\n"))
+	}
+
+	return envelopeHeader, itemHeaders, payloads
+}
+
+func TestSentryLevel(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("debug", SentryLevel(slog.LevelDebug))
+	is.Equal("info", SentryLevel(slog.LevelInfo))
+	is.Equal("warning", SentryLevel(slog.LevelWarn))
+	is.Equal("error", SentryLevel(slog.LevelError))
+	is.Equal("fatal", SentryLevel(slog.LevelError+4))
+	is.Equal("warning", SentryLevel(slog.LevelInfo+1))
+	is.Equal("error", SentryLevel(slog.LevelWarn+2))
+	is.Equal("fatal", SentryLevel(slog.LevelError+1))
+}
+
+func TestRecordToSentryEvent(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := slog.NewRecord(now, slog.LevelError, "request failed", 0)
+
+	cause := newTestStackError("connection refused")
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/users?id=42", nil)
+	req.Header.Set("X-Request-Id", "abc")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=abc")
+	req.Header.Set("Proxy-Authorization", "Basic secret")
+
+	attrs := []slog.Attr{
+		slog.Any("error", fmt.Errorf("db: %w", cause)),
+		slog.Any("request", req),
+		slog.Group("user", slog.String("id", "u-1"), slog.String("email", "a@b.c"), slog.String("plan", "pro")),
+		slog.String("component", "db"),
+		slog.Int("retries", 3),
+	}
+
+	event := RecordToSentryEvent(&record, attrs, SentryOptions{
+		TagKeys:       []string{"component"},
+		InAppPrefixes: []string{"github.com/samber/slog-common"},
+		Environment:   "prod",
+		EventID:       func() string { return "0123456789abcdef0123456789abcdef" },
+		Fingerprint: func(record *slog.Record, attrs []slog.Attr) []string {
+			return []string{"{{ default }}", record.Message}
+		},
+	})
+
+	is.Equal("0123456789abcdef0123456789abcdef", event.EventID)
+	is.Equal("2024-01-02T03:04:05Z", event.Timestamp)
+	is.Equal("error", event.Level)
+	is.Equal("go", event.Platform)
+	is.Equal("prod", event.Environment)
+	is.Equal("request failed", event.Message)
+	is.Equal([]string{"{{ default }}", "request failed"}, event.Fingerprint)
+
+	is.Len(event.Exception.Values, 2)
+	is.Equal("*slogcommon.testStackError", event.Exception.Values[0].Type)
+	is.Equal("connection refused", event.Exception.Values[0].Value)
+	is.Equal("*fmt.wrapError", event.Exception.Values[1].Type)
+	is.Equal("db: connection refused", event.Exception.Values[1].Value)
+	is.Nil(event.Exception.Values[1].Stacktrace)
+
+	frames := event.Exception.Values[0].Stacktrace.Frames
+	is.NotEmpty(frames)
+	last := frames[len(frames)-1]
+	is.Equal("github.com/samber/slog-common", last.Module)
+	is.Equal("TestRecordToSentryEvent", last.Function)
+	is.Equal("sentry_test.go", last.Filename)
+	is.True(last.InApp)
+	is.False(frames[0].InApp)
+
+	is.Equal(&SentryRequest{
+		URL:         "https://example.com/users",
+		Method:      "GET",
+		QueryString: "id=42",
+		Headers:     map[string]string{"X-Request-Id": "abc"},
+	}, event.Request)
+	is.NotContains(event.Request.Headers, "Authorization")
+	is.NotContains(event.Request.Headers, "Cookie")
+	is.Equal(map[string]string{"id": "u-1", "email": "a@b.c"}, event.User)
+	is.Equal(map[string]string{"component": "db"}, event.Tags)
+	is.Equal(map[string]any{"user": map[string]any{"plan": "pro"}, "retries": int64(3)}, event.Extra)
+}
+
+type testSentryAnyError struct {
+	value any
+}
+
+func (e testSentryAnyError) Error() string { return "any" }
+
+func TestSentryExceptionsFromError(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	// comparable type holding an uncomparable value
+	err := fmt.Errorf("wrapped: %w", testSentryAnyError{value: []int{1}})
+	exceptions := SentryExceptionsFromError(err, nil)
+	is.Len(exceptions, 2)
+	is.Equal("slogcommon.testSentryAnyError", exceptions[0].Type)
+
+	// errors.Join branches are followed
+	joined := fmt.Errorf("outer: %w", errors.Join(errors.New("a"), fmt.Errorf("b: %w", io.EOF)))
+	exceptions = SentryExceptionsFromError(joined, nil)
+	values := []string{}
+	for _, e := range exceptions {
+		values = append(values, e.Value)
+	}
+	is.Equal([]string{"EOF", "b: EOF", "a", "a\nb: EOF", "outer: a\nb: EOF"}, values)
+}
+
+func TestSentryEnvelope(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	record := slog.NewRecord(time.Now(), slog.LevelWarn, "hello\nworld", 0)
+	event := RecordToSentryEvent(&record, []slog.Attr{slog.String("foo", "bar")}, SentryOptions{})
+	is.Len(event.EventID, 32)
+	is.Nil(event.Exception)
+	is.Nil(event.User)
+
+	envelope, err := SentryEnvelope(event, "https://key@sentry.example.com/1")
+	is.NoError(err)
+
+	header, items, payloads := parseTestSentryEnvelope(t, envelope)
+	is.Equal(event.EventID, header["event_id"])
+	is.Equal("https://key@sentry.example.com/1", header["dsn"])
+	is.Len(items, 1)
+	is.Equal("event", items[0]["type"])
+	is.Equal(strconv.Itoa(len(payloads[0])), fmt.Sprint(items[0]["length"]))
+
+	var decoded SentryEvent
+	is.NoError(json.Unmarshal(payloads[0], &decoded))
+	is.Equal(event, decoded)
+	is.True(strings.HasSuffix(string(envelope), "\n"))
+}
+
+func TestSplitFunctionName(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	module, function := splitFunctionName("github.com/samber/slog-common.(*T).Method")
+	is.Equal("github.com/samber/slog-common", module)
+	is.Equal("(*T).Method", function)
+
+	module, function = splitFunctionName("runtime.goexit")
+	is.Equal("runtime", module)
+	is.Equal("goexit", function)
+
+	is.True(isStdlibFunction("net/http.(*Server).Serve"))
+	is.False(isStdlibFunction("github.com/a/b.F"))
+	is.False(isStdlibFunction("main.main"))
+}
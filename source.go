@@ -0,0 +1,191 @@
+package slogcommon
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+type SourcePath int
+
+const (
+	// SourcePathFull keeps the absolute path of the build machine.
+	SourcePathFull SourcePath = iota
+	// SourcePathModule renders files of the main module relative to the module
+	// root, eg: "internal/db/db.go". Files of other modules are prefixed with
+	// their package import path, eg: "github.com/samber/lo/map.go". Files of the
+	// main package keep their parent directory only.
+	SourcePathModule
+	// SourcePathBase keeps the file name only.
+	SourcePathBase
+)
+
+type SourceOptions struct {
+	Path SourcePath
+	// ShortFunction drops the import path but its last element, see
+	// ShortFunctionName.
+	ShortFunction bool
+	// Cache avoids symbolizing the same PC twice (optional).
+	Cache *SourceCache
+}
+
+// SourceCache memoizes PC to frame resolution. It is safe for concurrent use.
+type SourceCache struct {
+	frames sync.Map
+}
+
+func NewSourceCache() *SourceCache {
+	return &SourceCache{}
+}
+
+func (c *SourceCache) frame(pc uintptr) runtime.Frame {
+	if f, ok := c.frames.Load(pc); ok {
+		return f.(runtime.Frame)
+	}
+
+	f := sourceFrame(pc)
+	c.frames.Store(pc, f)
+	return f
+}
+
+func sourceFrame(pc uintptr) runtime.Frame {
+	fs := runtime.CallersFrames([]uintptr{pc})
+	f, _ := fs.Next()
+	return f
+}
+
+// Frame returns the frame of pc, with file and function rendered according to opts.
+func (opts SourceOptions) Frame(pc uintptr) runtime.Frame {
+	var f runtime.Frame
+	if opts.Cache != nil {
+		f = opts.Cache.frame(pc)
+	} else {
+		f = sourceFrame(pc)
+	}
+
+	return opts.format(f)
+}
+
+func (opts SourceOptions) format(f runtime.Frame) runtime.Frame {
+	if f.File != "" {
+		switch opts.Path {
+		case SourcePathModule:
+			f.File = sourceModulePath(sourceMainModule(), f.Function, f.File)
+		case SourcePathBase:
+			f.File = filepath.Base(f.File)
+		}
+	}
+
+	if opts.ShortFunction {
+		f.Function = ShortFunctionName(f.Function)
+	}
+
+	return f
+}
+
+// SourceWithOptions is a configurable variant of Source.
+func SourceWithOptions(sourceKey string, r *slog.Record, opts SourceOptions) slog.Attr {
+	f := opts.Frame(r.PC)
+
+	var args []any
+	if f.Function != "" {
+		args = append(args, slog.String("function", f.Function))
+	}
+	if f.File != "" {
+		args = append(args, slog.String("file", f.File))
+	}
+	if f.Line != 0 {
+		args = append(args, slog.Int("line", f.Line))
+	}
+
+	return slog.Group(sourceKey, args...)
+}
+
+// StringSourceWithOptions is a configurable variant of StringSource.
+func StringSourceWithOptions(sourceKey string, r *slog.Record, opts SourceOptions) slog.Attr {
+	f := opts.Frame(r.PC)
+	return slog.String(sourceKey, fmt.Sprintf("%s:%d (%s)", f.File, f.Line, f.Function))
+}
+
+// SlogSource returns a *slog.Source, as emitted by the stdlib handlers when
+// AddSource is enabled.
+func SlogSource(sourceKey string, r *slog.Record, opts SourceOptions) slog.Attr {
+	f := opts.Frame(r.PC)
+	return slog.Any(sourceKey, &slog.Source{
+		Function: f.Function,
+		File:     f.File,
+		Line:     f.Line,
+	})
+}
+
+// ShortFunctionName trims the import path of a function name, but its last
+// element: "github.com/samber/slog-common.(*T).Method" becomes
+// "slog-common.(*T).Method". The package name (here "slogcommon") is not
+// available from the function name, so it may differ.
+func ShortFunctionName(function string) string {
+	return function[strings.LastIndexByte(function, '/')+1:]
+}
+
+// sourcePackagePath extracts the import path from a function name. The import
+// path ends at the first dot after the last slash, except for gopkg.in style
+// ".vN" suffixes: "gopkg.in/yaml.v3.(*T).M" gives "gopkg.in/yaml.v3".
+func sourcePackagePath(function string) string {
+	end := strings.LastIndexByte(function, '/') + 1
+	for {
+		dot := strings.IndexByte(function[end:], '.')
+		if dot < 0 {
+			return ""
+		}
+		end += dot
+
+		if !isSourceMajorVersion(function[end+1:]) {
+			return function[:end]
+		}
+		end++
+	}
+}
+
+// isSourceMajorVersion reports whether s starts with "vN.".
+func isSourceMajorVersion(s string) bool {
+	if len(s) < 3 || s[0] != 'v' {
+		return false
+	}
+
+	i := 1
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	return i > 1 && i < len(s) && s[i] == '.'
+}
+
+// sourceMainModule returns the main module path, or "" when the binary was built
+// without module support.
+var sourceMainModule = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return info.Main.Path
+})
+
+func sourceModulePath(mainModule string, function string, file string) string {
+	dir, base := path.Split(filepath.ToSlash(file))
+
+	pkg := sourcePackagePath(function)
+	if pkg == "" || pkg == "main" {
+		return path.Base(dir) + "/" + base
+	}
+
+	if mainModule != "" && (pkg == mainModule || strings.HasPrefix(pkg, mainModule+"/")) {
+		rel := strings.TrimPrefix(strings.TrimPrefix(pkg, mainModule), "/")
+		return path.Join(rel, base)
+	}
+
+	return pkg + "/" + base
+}
@@ -0,0 +1,83 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSourceReceiver struct{}
+
+func (*testSourceReceiver) pc() uintptr {
+	pc, _, _, _ := runtime.Caller(0)
+	return pc
+}
+
+func TestSourceOptions(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	pc, file, line, _ := runtime.Caller(0)
+	record := &slog.Record{PC: pc}
+
+	f := SourceOptions{}.Frame(pc)
+	is.Equal(file, f.File)
+	is.Equal(line, f.Line)
+	is.Equal("github.com/samber/slog-common.TestSourceOptions", f.Function)
+
+	f = SourceOptions{Path: SourcePathModule, ShortFunction: true}.Frame(pc)
+	is.Equal("source_test.go", f.File)
+	is.Equal("slog-common.TestSourceOptions", f.Function)
+
+	f = SourceOptions{Path: SourcePathBase}.Frame((&testSourceReceiver{}).pc())
+	is.Equal("source_test.go", f.File)
+	is.Equal("github.com/samber/slog-common.(*testSourceReceiver).pc", f.Function)
+
+	opts := SourceOptions{Path: SourcePathBase, ShortFunction: true, Cache: NewSourceCache()}
+	is.Equal(
+		slog.Group("source",
+			slog.String("function", "slog-common.TestSourceOptions"),
+			slog.String("file", "source_test.go"),
+			slog.Int("line", line),
+		),
+		SourceWithOptions("source", record, opts),
+	)
+	// cached
+	is.Equal(SourceWithOptions("source", record, opts), SourceWithOptions("source", record, opts))
+	is.Equal(filepath.Base(file), StringSourceWithOptions("source", record, opts).Value.String()[:len("source_test.go")])
+
+	attr := SlogSource("source", record, opts)
+	is.Equal("source", attr.Key)
+	is.Equal(&slog.Source{Function: "slog-common.TestSourceOptions", File: "source_test.go", Line: line}, attr.Value.Any())
+
+	// unknown pc
+	is.Equal(slog.Group("source"), SourceWithOptions("source", &slog.Record{}, opts))
+}
+
+func TestShortFunctionName(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("", ShortFunctionName(""))
+	is.Equal("main.main", ShortFunctionName("main.main"))
+	is.Equal("lo.Map[...]", ShortFunctionName("github.com/samber/lo.Map[...]"))
+	is.Equal("v2.(*T).Method.func1", ShortFunctionName("example.com/a.b/v2.(*T).Method.func1"))
+
+	is.Equal("github.com/samber/lo", sourcePackagePath("github.com/samber/lo.Map[...]"))
+	is.Equal("example.com/a.b/v2", sourcePackagePath("example.com/a.b/v2.(*T).Method.func1"))
+	is.Equal("main", sourcePackagePath("main.main"))
+	is.Equal("gopkg.in/yaml.v3", sourcePackagePath("gopkg.in/yaml.v3.(*T).M"))
+	is.Equal("gopkg.in/yaml.v3", sourcePackagePath("gopkg.in/yaml.v3.v2"))
+	is.Equal("example.com/a", sourcePackagePath("example.com/a.vet.func1"))
+	is.Equal("yaml.v3.(*T).M", ShortFunctionName("gopkg.in/yaml.v3.(*T).M"))
+
+	is.Equal("cmd/app.go", sourceModulePath("example.com/app", "main.main", "/home/me/cmd/app.go"))
+	is.Equal("app.go", sourceModulePath("example.com/app", "example.com/app.Run", "/home/me/app/app.go"))
+	is.Equal("internal/db/db.go", sourceModulePath("example.com/app", "example.com/app/internal/db.Open", "/home/me/app/internal/db/db.go"))
+	is.Equal("example.com/application/db.go", sourceModulePath("example.com/app", "example.com/application.Open", "/home/me/application/db.go"))
+	is.Equal("github.com/samber/lo/map.go", sourceModulePath("example.com/app", "github.com/samber/lo.Map[...]", "/go/pkg/mod/github.com/samber/lo@v1.0.0/map.go"))
+	is.Equal("github.com/samber/lo/map.go", sourceModulePath("", "github.com/samber/lo.Map[...]", "/go/pkg/mod/github.com/samber/lo@v1.0.0/map.go"))
+}
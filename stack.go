@@ -0,0 +1,174 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const stackDefaultMaxFrames = 32
+
+type StackFormat int
+
+const (
+	// StackFormatFrames renders a []slog.Source, oldest call last.
+	StackFormatFrames StackFormat = iota
+	// StackFormatString renders frames like a Go panic:
+	//
+	//	main.handler(...)
+	//		/app/main.go:42
+	StackFormatString
+)
+
+type StackOptions struct {
+	// MaxFrames bounds the number of frames, after filtering. Defaults to 32.
+	MaxFrames int
+	// Include keeps frames whose function starts with one of the prefixes (optional).
+	Include []string
+	// Exclude drops frames whose function starts with one of the prefixes.
+	Exclude []string
+	Format  StackFormat
+	// Source configures file and function rendering.
+	Source SourceOptions
+}
+
+// StackAttr captures the stack of the caller.
+func StackAttr(key string, opts StackOptions) slog.Attr {
+	return slog.Any(key, opts.render(captureStack(3)))
+}
+
+// StackAttrFromPC captures the stack starting at pc, usually slog.Record.PC. It
+// must be called from the goroutine that logged the record, within the logging
+// call (eg: from slog.Handler.Handle). Otherwise, the single pc frame is used.
+func StackAttrFromPC(key string, pc uintptr, opts StackOptions) slog.Attr {
+	pcs := captureStack(3)
+	for i := range pcs {
+		if pcs[i] == pc {
+			return slog.Any(key, opts.render(pcs[i:]))
+		}
+	}
+
+	return slog.Any(key, opts.render([]uintptr{pc}))
+}
+
+// StackReplaceAttr returns a ReplaceAttrFn appending a stack attribute next to the
+// level attribute when the level is enabled. Frames of log/slog and callers are
+// skipped. The stdlib handlers inline the returned group since its key is empty.
+// The level is returned as a string named by DefaultLevelRegistry, so hooks
+// chained after this one no longer see a slog.Level.
+func StackReplaceAttr(key string, level slog.Leveler, opts StackOptions) ReplaceAttrFn {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 || a.Key != slog.LevelKey {
+			return a
+		}
+
+		l, ok := a.Value.Any().(slog.Level)
+		if !ok || l < level.Level() {
+			return a
+		}
+
+		pcs := captureStack(2)
+		for i := len(pcs) - 1; i >= 0; i-- {
+			if strings.HasPrefix(runtimeFunctionName(pcs[i]), "log/slog.") {
+				pcs = pcs[i+1:]
+				break
+			}
+		}
+
+		// The stdlib handlers call ReplaceAttr again on the attributes of the
+		// returned group: the level is rendered as a string to avoid looping.
+		return slog.Group("", slog.String(a.Key, DefaultLevelRegistry.Name(l)), slog.Any(key, opts.render(pcs)))
+	}
+}
+
+// StackFrames symbolizes and filters program counters.
+func (opts StackOptions) StackFrames(pcs []uintptr) []slog.Source {
+	maxFrames := opts.MaxFrames
+	if maxFrames <= 0 {
+		maxFrames = stackDefaultMaxFrames
+	}
+
+	frames := []slog.Source{}
+	if len(pcs) == 0 {
+		return frames
+	}
+
+	iter := runtime.CallersFrames(pcs)
+	for len(frames) < maxFrames {
+		f, more := iter.Next()
+		if f.Function != "" && opts.keep(f.Function) {
+			f = opts.Source.format(f)
+			frames = append(frames, slog.Source{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+
+	return frames
+}
+
+func (opts StackOptions) keep(function string) bool {
+	if len(opts.Include) > 0 && !hasAnyPrefix(function, opts.Include) {
+		return false
+	}
+
+	return !hasAnyPrefix(function, opts.Exclude)
+}
+
+func (opts StackOptions) render(pcs []uintptr) any {
+	frames := opts.StackFrames(pcs)
+	if opts.Format == StackFormatString {
+		return FormatStack(frames)
+	}
+
+	return frames
+}
+
+// FormatStack renders frames like a Go panic.
+func FormatStack(frames []slog.Source) string {
+	var sb strings.Builder
+
+	for _, f := range frames {
+		sb.WriteString(f.Function)
+		sb.WriteString("(...)\n\t")
+		sb.WriteString(f.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(f.Line))
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// captureStack skips runtime.Callers and captureStack itself when skip is 2.
+func captureStack(skip int) []uintptr {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip, pcs)
+		if n < len(pcs) {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+}
+
+func runtimeFunctionName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc - 1)
+	if fn == nil {
+		return ""
+	}
+
+	return fn.Name()
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package slogcommon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStackHandler struct {
+	slog.Handler
+	attr slog.Attr
+}
+
+func (h *testStackHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.attr = StackAttrFromPC("stack", r.PC, StackOptions{Include: []string{"github.com/samber/slog-common."}})
+	return nil
+}
+
+func TestStackAttr(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	_, file, line, _ := runtime.Caller(0)
+	attr := StackAttr("stack", StackOptions{MaxFrames: 2})
+	is.Equal("stack", attr.Key)
+
+	frames := attr.Value.Any().([]slog.Source)
+	is.Len(frames, 2)
+	is.Equal(slog.Source{Function: "github.com/samber/slog-common.TestStackAttr", File: file, Line: line + 1}, frames[0])
+	is.Equal("testing.tRunner", frames[1].Function)
+
+	// filters
+	attr = StackAttr("stack", StackOptions{Exclude: []string{"testing.", "runtime."}, Source: SourceOptions{Path: SourcePathBase, ShortFunction: true}})
+	is.Equal([]slog.Source{{Function: "slog-common.TestStackAttr", File: "stack_test.go", Line: line + 10}}, attr.Value.Any())
+
+	attr = StackAttr("stack", StackOptions{Include: []string{"testing."}, Format: StackFormatString})
+	is.True(strings.HasPrefix(attr.Value.String(), "testing.tRunner(...)\n\t"))
+	is.True(strings.HasSuffix(attr.Value.String(), "\n"))
+
+	is.Equal("a.B(...)\n\t/a/b.go:1\nc.D(...)\n\t/c/d.go:2\n", FormatStack([]slog.Source{{Function: "a.B", File: "/a/b.go", Line: 1}, {Function: "c.D", File: "/c/d.go", Line: 2}}))
+	is.Equal([]slog.Source{}, StackOptions{}.StackFrames(nil))
+}
+
+func TestStackAttrFromPC(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	h := &testStackHandler{Handler: slog.NewTextHandler(&bytes.Buffer{}, nil)}
+	_, _, line, _ := runtime.Caller(0)
+	slog.New(h).Info("hello")
+
+	frames := h.attr.Value.Any().([]slog.Source)
+	is.NotEmpty(frames)
+	is.Equal("github.com/samber/slog-common.TestStackAttrFromPC", frames[0].Function)
+	is.Equal(line+1, frames[0].Line)
+
+	// unknown pc, from another call chain
+	pc, _, _, _ := runtime.Caller(0)
+	frames = StackAttrFromPC("stack", pc+1, StackOptions{}).Value.Any().([]slog.Source)
+	is.Len(frames, 1)
+}
+
+func TestStackReplaceAttr(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: StackReplaceAttr("stack", slog.LevelError, StackOptions{MaxFrames: 1, Source: SourceOptions{Path: SourcePathBase}}),
+	}))
+
+	logger.Warn("warn")
+	_, _, line, _ := runtime.Caller(0)
+	logger.Error("error")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	is.Len(lines, 2)
+
+	var warn, err map[string]any
+	is.NoError(json.Unmarshal([]byte(lines[0]), &warn))
+	is.NoError(json.Unmarshal([]byte(lines[1]), &err))
+
+	is.NotContains(warn, "stack")
+	is.Equal("ERROR", err["level"])
+	is.Equal([]any{map[string]any{
+		"function": "github.com/samber/slog-common.TestStackReplaceAttr",
+		"file":     "stack_test.go",
+		"line":     float64(line + 1),
+	}}, err["stack"])
+
+	// levels are named by DefaultLevelRegistry
+	buf.Reset()
+	logger.Log(context.Background(), LevelFatal, "fatal")
+	var fatal map[string]any
+	is.NoError(json.Unmarshal(buf.Bytes(), &fatal))
+	is.Equal("FATAL", fatal["level"])
+}
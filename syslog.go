@@ -0,0 +1,253 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	syslogNilValue     = "-"
+	syslogTimestampFmt = "2006-01-02T15:04:05.000000Z07:00"
+	syslogMaxHostname  = 255
+	syslogMaxAppName   = 48
+	syslogMaxProcID    = 128
+	syslogMaxMsgID     = 32
+	syslogMaxSDName    = 32
+	syslogMaxFacility  = 23
+	// RFC 5612 documentation number, used when EnterpriseNumber is empty
+	syslogDefaultEnterpriseNumber = "32473"
+)
+
+// Syslog facilities. Since zero selects the default facility, kern is represented
+// by a negative value.
+const (
+	SyslogFacilityKern   = -1
+	SyslogFacilityUser   = 1
+	SyslogFacilityDaemon = 3
+	SyslogFacilityAuth   = 4
+	SyslogFacilityLocal0 = 16
+	SyslogFacilityLocal7 = 23
+)
+
+// syslogIANASDIDs are the SD-IDs registered by RFC 5424, which carry no enterprise
+// number.
+var syslogIANASDIDs = []string{"timeQuality", "origin", "meta"}
+
+type SyslogOptions struct {
+	// Facility defaults to SyslogFacilityUser. Values outside 0-23 fall back to the
+	// default. Use SyslogFacilityKern for kern (0).
+	Facility int
+	Hostname string
+	AppName  string
+	// ProcID defaults to the current process id.
+	ProcID string
+	MsgID  string
+
+	// EnterpriseNumber is appended to SD-IDs that are not IANA registered, as
+	// required by RFC 5424. Defaults to "32473", reserved for documentation.
+	EnterpriseNumber string
+	// DefaultSDID is the SD-ID used for top-level attributes that are not groups. When
+	// empty, such attributes are dropped.
+	DefaultSDID string
+}
+
+// SyslogSeverity maps a slog.Level to a RFC 5424 severity (0=Emergency, 7=Debug),
+// following SyslogLevels.
+func SyslogSeverity(level slog.Level) int {
+	return SyslogLevels.Map(level)
+}
+
+// RecordToSyslog renders a RFC 5424 message. Top-level groups become SD-ELEMENTs,
+// nested groups are flattened into dotted PARAM-NAMEs and groups with an empty key
+// are inlined. attrs is the full attribute set, usually built with
+// AppendRecordAttrsToAttrs.
+func RecordToSyslog(record *slog.Record, attrs []slog.Attr, opts SyslogOptions) []byte {
+	facility := opts.Facility
+	switch {
+	case facility == SyslogFacilityKern:
+		facility = 0
+	case facility <= 0 || facility > syslogMaxFacility:
+		facility = SyslogFacilityUser
+	}
+
+	procID := opts.ProcID
+	if procID == "" {
+		procID = strconv.Itoa(os.Getpid())
+	}
+
+	buf := make([]byte, 0, 256)
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(facility*8+SyslogSeverity(record.Level)), 10)
+	buf = append(buf, ">1 "...)
+
+	if record.Time.IsZero() {
+		buf = append(buf, syslogNilValue...)
+	} else {
+		buf = record.Time.AppendFormat(buf, syslogTimestampFmt)
+	}
+
+	buf = append(buf, ' ')
+	buf = appendSyslogHeaderField(buf, opts.Hostname, syslogMaxHostname)
+	buf = append(buf, ' ')
+	buf = appendSyslogHeaderField(buf, opts.AppName, syslogMaxAppName)
+	buf = append(buf, ' ')
+	buf = appendSyslogHeaderField(buf, procID, syslogMaxProcID)
+	buf = append(buf, ' ')
+	buf = appendSyslogHeaderField(buf, opts.MsgID, syslogMaxMsgID)
+	buf = append(buf, ' ')
+	buf = AppendSyslogStructuredData(buf, attrs, opts)
+
+	if record.Message != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, "\xEF\xBB\xBF"...)
+		buf = append(buf, record.Message...)
+	}
+
+	return buf
+}
+
+func AppendSyslogStructuredData(buf []byte, attrs []slog.Attr, opts SyslogOptions) []byte {
+	start := len(buf)
+
+	var defaultParams []slog.Attr
+	for _, attr := range mergeAttrsByKey(inlineSyslogGroups(nil, attrs)) {
+		if attr.Value.Kind() != slog.KindGroup {
+			defaultParams = append(defaultParams, attr)
+			continue
+		}
+
+		buf = appendSyslogSDElement(buf, attr.Key, attr.Value.Group(), opts.EnterpriseNumber)
+	}
+
+	if opts.DefaultSDID != "" && len(defaultParams) > 0 {
+		buf = appendSyslogSDElement(buf, opts.DefaultSDID, defaultParams, opts.EnterpriseNumber)
+	}
+
+	if len(buf) == start {
+		buf = append(buf, syslogNilValue...)
+	}
+
+	return buf
+}
+
+func appendSyslogSDElement(buf []byte, id string, params []slog.Attr, enterpriseNumber string) []byte {
+	id = SyslogSDName(id)
+	if id == "" {
+		return buf
+	}
+
+	if enterpriseNumber == "" {
+		enterpriseNumber = syslogDefaultEnterpriseNumber
+	}
+
+	if suffix := "@" + enterpriseNumber; !strings.Contains(id, "@") && !slices.Contains(syslogIANASDIDs, id) {
+		// keep the enterprise number when the name is truncated
+		if len(id)+len(suffix) > syslogMaxSDName {
+			id = id[:max(0, syslogMaxSDName-len(suffix))]
+		}
+		id = SyslogSDName(id + suffix)
+	}
+
+	buf = append(buf, '[')
+	buf = append(buf, id...)
+	buf = appendSyslogSDParams(buf, "", params)
+	buf = append(buf, ']')
+
+	return buf
+}
+
+func appendSyslogSDParams(buf []byte, prefix string, params []slog.Attr) []byte {
+	for _, attr := range mergeAttrsByKey(inlineSyslogGroups(nil, params)) {
+		key := prefix + attr.Key
+
+		if attr.Value.Kind() == slog.KindGroup {
+			buf = appendSyslogSDParams(buf, key+".", attr.Value.Group())
+			continue
+		}
+
+		name := SyslogSDName(key)
+		if name == "" {
+			continue
+		}
+
+		buf = append(buf, ' ')
+		buf = append(buf, name...)
+		buf = append(buf, `="`...)
+		buf = appendSyslogParamValue(buf, ValueToString(attr.Value))
+		buf = append(buf, '"')
+	}
+
+	return buf
+}
+
+// inlineSyslogGroups flattens groups with an empty key, like slog handlers do.
+func inlineSyslogGroups(output []slog.Attr, attrs []slog.Attr) []slog.Attr {
+	for _, attr := range attrs {
+		if attr.Key == "" {
+			if v := attr.Value.Resolve(); v.Kind() == slog.KindGroup {
+				output = inlineSyslogGroups(output, v.Group())
+				continue
+			}
+		}
+		output = append(output, attr)
+	}
+
+	return output
+}
+
+// SyslogSDName sanitizes a SD-ID or PARAM-NAME: printable US-ASCII except '=', ' ',
+// ']' and '"', at most 32 characters. Invalid characters are replaced by '_'.
+func SyslogSDName(name string) string {
+	var sb strings.Builder
+	for i := 0; i < len(name) && sb.Len() < syslogMaxSDName; i++ {
+		c := name[i]
+		if c <= 32 || c >= 127 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+func appendSyslogParamValue(buf []byte, value string) []byte {
+	if !utf8.ValidString(value) {
+		value = strings.ToValidUTF8(value, "�")
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '"' || c == '\\' || c == ']' {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, c)
+	}
+
+	return buf
+}
+
+func appendSyslogHeaderField(buf []byte, value string, maxLen int) []byte {
+	start := len(buf)
+	for i := 0; i < len(value) && len(buf)-start < maxLen; i++ {
+		if c := value[i]; c >= 33 && c <= 126 {
+			buf = append(buf, c)
+		}
+	}
+
+	if len(buf) == start {
+		buf = append(buf, syslogNilValue...)
+	}
+
+	return buf
+}
+
+// AppendSyslogOctetCounting frames a message for TCP transport (RFC 6587): "LEN SP MSG".
+func AppendSyslogOctetCounting(dst []byte, msg []byte) []byte {
+	dst = strconv.AppendInt(dst, int64(len(msg)), 10)
+	dst = append(dst, ' ')
+	return append(dst, msg...)
+}
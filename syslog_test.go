@@ -0,0 +1,102 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogSeverity(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal(7, SyslogSeverity(slog.LevelDebug))
+	is.Equal(7, SyslogSeverity(slog.LevelDebug-4))
+	is.Equal(6, SyslogSeverity(slog.LevelInfo))
+	is.Equal(5, SyslogSeverity(slog.LevelInfo+2))
+	is.Equal(4, SyslogSeverity(slog.LevelWarn))
+	is.Equal(3, SyslogSeverity(slog.LevelError))
+	is.Equal(2, SyslogSeverity(slog.LevelError+4))
+	is.Equal(1, SyslogSeverity(slog.LevelError+8))
+	is.Equal(0, SyslogSeverity(slog.LevelError+12))
+
+	// in-between levels map to the next-higher severity
+	is.Equal(5, SyslogSeverity(slog.LevelInfo+1))
+	is.Equal(3, SyslogSeverity(slog.LevelWarn+2))
+	is.Equal(2, SyslogSeverity(slog.LevelError+1))
+}
+
+func TestRecordToSyslog(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	record := slog.NewRecord(now, slog.LevelError, "hello world", 0)
+
+	attrs := []slog.Attr{
+		slog.String("top", "level"),
+		slog.Group("http", slog.String("method", "GET"), slog.Group("req", slog.Int("size", 42))),
+		slog.Group("quote", slog.String("value", `a"b\c]d`)),
+		slog.Group("bad name=", slog.String("k ey", "v")),
+	}
+
+	opts := SyslogOptions{
+		Hostname:         "host.example.com",
+		AppName:          "my app",
+		ProcID:           "1234",
+		MsgID:            "ID47",
+		EnterpriseNumber: "32473",
+	}
+
+	is.Equal(
+		`<11>1 2024-01-02T03:04:05.000006Z host.example.com myapp 1234 ID47 [http@32473 method="GET" req.size="42"][quote@32473 value="a\"b\\c\]d"][bad_name_@32473 k_ey="v"]`+" \xEF\xBB\xBFhello world",
+		string(RecordToSyslog(&record, attrs, opts)),
+	)
+
+	// default SD-ID and nil values
+	opts = SyslogOptions{Facility: 16, ProcID: "1", DefaultSDID: "meta"}
+	record = slog.NewRecord(time.Time{}, slog.LevelInfo, "", 0)
+	is.Equal(
+		`<134>1 - - - 1 - [meta top="level"]`,
+		string(RecordToSyslog(&record, attrs[:1], opts)),
+	)
+	is.Equal(
+		`<134>1 - - - 1 - -`,
+		string(RecordToSyslog(&record, attrs[:1], SyslogOptions{Facility: 16, ProcID: "1"})),
+	)
+
+	// facilities
+	is.Equal("<6>1 - - - 1 - -", string(RecordToSyslog(&record, nil, SyslogOptions{Facility: SyslogFacilityKern, ProcID: "1"})))
+	is.Equal("<14>1 - - - 1 - -", string(RecordToSyslog(&record, nil, SyslogOptions{Facility: 24, ProcID: "1"})))
+	is.Equal("<14>1 - - - 1 - -", string(RecordToSyslog(&record, nil, SyslogOptions{Facility: -2, ProcID: "1"})))
+	is.Equal("<190>1 - - - 1 - -", string(RecordToSyslog(&record, nil, SyslogOptions{Facility: SyslogFacilityLocal7, ProcID: "1"})))
+
+	// custom SD-IDs get the default enterprise number, inlined groups are flattened
+	is.Equal(
+		`<14>1 - - - 1 - [origin ip="1.2.3.4"][http@32473 method="GET" status="200"][meta a="b"]`,
+		string(RecordToSyslog(&record, []slog.Attr{
+			slog.Group("origin", slog.String("ip", "1.2.3.4")),
+			slog.Group("", slog.Group("http", slog.String("method", "GET"), slog.Group("", slog.Int("status", 200))), slog.String("a", "b")),
+		}, SyslogOptions{ProcID: "1", DefaultSDID: "meta"})),
+	)
+}
+
+func TestSyslogSDName(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("origin", SyslogSDName("origin"))
+	is.Equal("a_b_c_d_", SyslogSDName(`a b=c]d"`))
+	is.Equal("__", SyslogSDName("é"))
+	is.Len(SyslogSDName("abcdefghijklmnopqrstuvwxyz0123456789"), 32)
+}
+
+func TestAppendSyslogOctetCounting(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	is.Equal("5 hello", string(AppendSyslogOctetCounting(nil, []byte("hello"))))
+	is.Equal("x0 ", string(AppendSyslogOctetCounting([]byte("x"), nil)))
+}
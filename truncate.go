@@ -0,0 +1,199 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"reflect"
+	"unicode/utf8"
+)
+
+const (
+	DefaultTruncateSuffix    = "…"
+	DefaultTruncateMarkerKey = "_truncated"
+)
+
+// AttrLimits bounds the size of an attribute tree. Zero means unlimited.
+type AttrLimits struct {
+	// MaxStringLength bounds KindString values, in runes (suffix included). The
+	// suffix is dropped when it is longer than the limit. Strings held by KindAny
+	// values (slices, maps, fmt.Stringer...) are not limited, use MaxSize to bound
+	// them.
+	MaxStringLength int
+	// MaxAttrs bounds the number of attributes at each level.
+	MaxAttrs int
+	// MaxDepth bounds group nesting. Deeper groups are replaced by the suffix.
+	MaxDepth int
+	// MaxSliceLength bounds slices and arrays held by KindAny values.
+	MaxSliceLength int
+	// MaxSize bounds the total size in bytes, estimated as the sum of keys and
+	// ValueToString outputs. The marker attribute is accounted for.
+	MaxSize int
+
+	// Suffix is appended to truncated strings. Defaults to "…".
+	Suffix string
+	// MarkerKey is the key of the boolean attribute appended at the top level when
+	// anything was truncated. Defaults to "_truncated".
+	MarkerKey string
+}
+
+// TruncateAttrs enforces the limits, walking attributes in order so that the
+// output is deterministic. LogValuers are resolved with SafeResolveAttrs, so
+// recursive values are cut even without MaxDepth. The returned boolean reports
+// whether anything was truncated.
+func TruncateAttrs(limits AttrLimits, attrs ...slog.Attr) ([]slog.Attr, bool) {
+	if limits.Suffix == "" {
+		limits.Suffix = DefaultTruncateSuffix
+	}
+	if limits.MarkerKey == "" {
+		limits.MarkerKey = DefaultTruncateMarkerKey
+	}
+
+	marker := slog.StringValue(limits.Suffix)
+	attrs, resolveTruncated := SafeResolveAttrs(
+		SafeResolveOptions{MaxDepth: max(limits.MaxDepth+1, safeResolveDefaultMaxDepth), Marker: &marker},
+		attrs...,
+	)
+
+	t := truncator{limits: limits, remaining: limits.MaxSize, truncated: resolveTruncated}
+	if limits.MaxSize > 0 {
+		t.remaining -= len(limits.MarkerKey) + len("true")
+	}
+
+	output := t.attrs(attrs, 0)
+	if t.truncated {
+		output = append(output, slog.Bool(limits.MarkerKey, true))
+	}
+
+	return output, t.truncated
+}
+
+type truncator struct {
+	limits    AttrLimits
+	remaining int
+	exhausted bool
+	truncated bool
+}
+
+func (t *truncator) attrs(attrs []slog.Attr, depth int) []slog.Attr {
+	output := make([]slog.Attr, 0, len(attrs))
+
+	for i, attr := range attrs {
+		if t.exhausted || (t.limits.MaxAttrs > 0 && i >= t.limits.MaxAttrs) {
+			t.truncated = true
+			break
+		}
+
+		v := attr.Value.Resolve()
+
+		if v.Kind() == slog.KindGroup && (t.limits.MaxDepth <= 0 || depth < t.limits.MaxDepth) {
+			if !t.consume(len(attr.Key)) {
+				break
+			}
+			output = append(output, slog.Attr{Key: attr.Key, Value: slog.GroupValue(t.attrs(v.Group(), depth+1)...)})
+			continue
+		}
+
+		if v.Kind() == slog.KindGroup {
+			t.truncated = true
+			v = slog.StringValue(t.limits.Suffix)
+		}
+
+		v, ok := t.leaf(attr.Key, v)
+		if !ok {
+			break
+		}
+		output = append(output, slog.Attr{Key: attr.Key, Value: v})
+	}
+
+	return output
+}
+
+func (t *truncator) leaf(key string, v slog.Value) (slog.Value, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		s := v.String()
+		if t.limits.MaxStringLength > 0 && utf8.RuneCountInString(s) > t.limits.MaxStringLength {
+			t.truncated = true
+			s = truncateWithSuffix(s, t.limits.MaxStringLength, t.limits.Suffix)
+		}
+
+		// a string can be shortened to fit the remaining budget
+		if t.limits.MaxSize > 0 && len(key)+len(s) > t.remaining {
+			budget := t.remaining - len(key) - len(t.limits.Suffix)
+			if budget < 0 {
+				t.exhaust()
+				return v, false
+			}
+			t.truncated = true
+			s = truncateBytes(s, budget) + t.limits.Suffix
+		}
+
+		t.consume(len(key) + len(s))
+		return slog.StringValue(s), true
+
+	case slog.KindAny:
+		v = t.slice(v)
+	}
+
+	if !t.consume(len(key) + len(ValueToString(v))) {
+		return v, false
+	}
+
+	return v, true
+}
+
+func (t *truncator) slice(v slog.Value) slog.Value {
+	if t.limits.MaxSliceLength <= 0 || v.Any() == nil {
+		return v
+	}
+
+	rv := reflect.ValueOf(v.Any())
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() <= t.limits.MaxSliceLength {
+		return v
+	}
+
+	t.truncated = true
+
+	if rv.Kind() == reflect.Slice {
+		return slog.AnyValue(rv.Slice(0, t.limits.MaxSliceLength).Interface())
+	}
+
+	output := make([]any, 0, t.limits.MaxSliceLength)
+	for i := 0; i < t.limits.MaxSliceLength; i++ {
+		output = append(output, rv.Index(i).Interface())
+	}
+
+	return slog.AnyValue(output)
+}
+
+// consume charges n bytes to the size budget. Once the budget is exceeded, the
+// remaining attributes are dropped.
+func (t *truncator) consume(n int) bool {
+	if t.limits.MaxSize <= 0 {
+		return true
+	}
+	if n > t.remaining {
+		t.exhaust()
+		return false
+	}
+
+	t.remaining -= n
+	return true
+}
+
+func (t *truncator) exhaust() {
+	t.exhausted = true
+	t.truncated = true
+}
+
+// truncateBytes cuts s to at most n bytes, on a rune boundary.
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
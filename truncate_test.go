@@ -0,0 +1,120 @@
+package slogcommon
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateAttrs(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	// no limits
+	attrs := []slog.Attr{slog.String("a", "hello"), slog.Group("g", slog.Int("i", 1))}
+	output, truncated := TruncateAttrs(AttrLimits{}, attrs...)
+	is.False(truncated)
+	is.Equal(attrs, output)
+
+	// string length
+	output, truncated = TruncateAttrs(AttrLimits{MaxStringLength: 4}, slog.String("a", "hello"), slog.String("b", "héé"))
+	is.True(truncated)
+	is.Equal([]slog.Attr{slog.String("a", "hel…"), slog.String("b", "héé"), slog.Bool("_truncated", true)}, output)
+
+	// suffix longer than the limit
+	output, truncated = TruncateAttrs(AttrLimits{MaxStringLength: 2, Suffix: "..."}, slog.String("a", "hello"))
+	is.True(truncated)
+	is.Equal([]slog.Attr{slog.String("a", "he"), slog.Bool("_truncated", true)}, output)
+
+	// attr count, per level
+	output, truncated = TruncateAttrs(
+		AttrLimits{MaxAttrs: 2, Suffix: "...", MarkerKey: "cut"},
+		slog.Group("g", slog.Int("a", 1), slog.Int("b", 2), slog.Int("c", 3)),
+		slog.Int("x", 1),
+		slog.Int("y", 2),
+	)
+	is.True(truncated)
+	is.Equal([]slog.Attr{
+		slog.Group("g", slog.Int("a", 1), slog.Int("b", 2)),
+		slog.Int("x", 1),
+		slog.Bool("cut", true),
+	}, output)
+
+	// group depth
+	output, truncated = TruncateAttrs(AttrLimits{MaxDepth: 1}, slog.Group("a", slog.Group("b", slog.Int("c", 1)), slog.Int("d", 2)))
+	is.True(truncated)
+	is.Equal([]slog.Attr{
+		slog.Group("a", slog.String("b", "…"), slog.Int("d", 2)),
+		slog.Bool("_truncated", true),
+	}, output)
+
+	// slice length
+	output, truncated = TruncateAttrs(
+		AttrLimits{MaxSliceLength: 2},
+		slog.Any("s", []int{1, 2, 3}),
+		slog.Any("a", [3]string{"a", "b", "c"}),
+		slog.Any("short", []int{1}),
+	)
+	is.True(truncated)
+	is.Equal([]slog.Attr{
+		slog.Any("s", []int{1, 2}),
+		slog.Any("a", []any{"a", "b"}),
+		slog.Any("short", []int{1}),
+		slog.Bool("_truncated", true),
+	}, output)
+
+	// LogValuers are resolved
+	output, truncated = TruncateAttrs(AttrLimits{MaxStringLength: 3}, slog.Any("user", stubLogValuer))
+	is.True(truncated)
+	is.Equal([]slog.Attr{
+		slog.Group("user", slog.String("name", "us…"), slog.String("password", "**…")),
+		slog.Bool("_truncated", true),
+	}, output)
+
+	// recursive LogValuers are cut without MaxDepth
+	output, truncated = TruncateAttrs(AttrLimits{MaxStringLength: 10}, slog.Any("x", testRecursiveLogValuer{}))
+	is.True(truncated)
+	is.Len(output, 2)
+	is.Equal("_truncated", output[1].Key)
+}
+
+func TestTruncateAttrsMaxSize(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	// 14 bytes for the marker, 20 bytes for attributes
+	limits := AttrLimits{MaxSize: 34}
+
+	output, truncated := TruncateAttrs(limits, slog.Int("a", 1), slog.String("b", "0123456789abcdefghij"), slog.Int("c", 1))
+	is.True(truncated)
+	is.Equal([]slog.Attr{
+		slog.Int("a", 1),
+		slog.String("b", "0123456789abcd…"),
+		slog.Bool("_truncated", true),
+	}, output)
+	is.Equal("0123456789abcd…", output[1].Value.String())
+
+	size := 0
+	for _, attr := range output {
+		size += len(attr.Key) + len(ValueToString(attr.Value))
+	}
+	is.LessOrEqual(size, limits.MaxSize)
+
+	// non-string values are dropped when they don't fit
+	output, truncated = TruncateAttrs(limits, slog.String("a", "0123456789abcdef"), slog.Int("b", 12345))
+	is.True(truncated)
+	is.Equal([]slog.Attr{slog.String("a", "0123456789abcdef"), slog.Bool("_truncated", true)}, output)
+	is.Equal("0123456789abcdef", output[0].Value.String())
+
+	// fits
+	output, truncated = TruncateAttrs(limits, slog.Group("g", slog.Int("a", 1)))
+	is.False(truncated)
+	is.Equal([]slog.Attr{slog.Group("g", slog.Int("a", 1))}, output)
+
+	// deterministic
+	attrs := []slog.Attr{slog.String("a", "héhéhéhéhéhéhé"), slog.Group("g", slog.String("b", "xyz"))}
+	first, _ := TruncateAttrs(AttrLimits{MaxSize: 25}, attrs...)
+	second, _ := TruncateAttrs(AttrLimits{MaxSize: 25}, attrs...)
+	is.Equal(first, second)
+}